@@ -0,0 +1,236 @@
+package gon
+
+import (
+	"net/http"
+	"testing"
+)
+
+// mockResponseWriter 是一个不做任何事情的 http.ResponseWriter，用于避免基准测试统计到响应写入的开销
+type mockResponseWriter struct {
+	header http.Header
+}
+
+func newMockWriter() *mockResponseWriter {
+	return &mockResponseWriter{header: http.Header{}}
+}
+
+func (m *mockResponseWriter) Header() http.Header {
+	return m.header
+}
+
+func (m *mockResponseWriter) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+func (m *mockResponseWriter) WriteString(s string) (n int, err error) {
+	return len(s), nil
+}
+
+func (m *mockResponseWriter) WriteHeader(int) {}
+
+// newBenchEngine 返回注册了 routes 中所有路由的 Engine，所有路由的处理函数都不做任何事情
+func newBenchEngine(routes []route) *Engine {
+	router := New()
+	for _, route := range routes {
+		router.Handler(route.method, route.path, func(c *Context) {})
+	}
+	return router
+}
+
+func newRequest(method, path string) *http.Request {
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		panic(err)
+	}
+	return req
+}
+
+func benchRequest(b *testing.B, router http.Handler, req *http.Request) {
+	w := newMockWriter()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.ServeHTTP(w, req)
+	}
+}
+
+func benchRoutes(b *testing.B, router http.Handler, routes []route) {
+	w := newMockWriter()
+	requests := make([]*http.Request, len(routes))
+	for i, route := range routes {
+		requests[i] = newRequest(route.method, route.path)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, req := range requests {
+			router.ServeHTTP(w, req)
+		}
+	}
+}
+
+// assertZeroAllocs 检查使用 router 处理 requests 中的请求时没有任何内存分配
+func assertZeroAllocs(t *testing.T, router http.Handler, requests ...*http.Request) {
+	t.Helper()
+	if raceEnabled {
+		t.Skip("skipping allocation checks with race detector enabled")
+	}
+	w := newMockWriter()
+	for _, req := range requests {
+		allocs := testing.AllocsPerRun(100, func() {
+			router.ServeHTTP(w, req)
+		})
+		if allocs != 0 {
+			t.Errorf("%s %s: got %v allocs per request, want 0", req.Method, req.URL.Path, allocs)
+		}
+	}
+}
+
+func TestServeHTTPZeroAllocs(t *testing.T) {
+	router := newBenchEngine(githubAPI)
+	assertZeroAllocs(t, router,
+		newRequest(http.MethodGet, "/user/repos"),
+		newRequest(http.MethodGet, "/repos/julienschmidt/httprouter/stargazers"),
+		newRequest(http.MethodGet, "/repos/julienschmidt/httprouter/git/refs/heads/master"),
+		newRequest(http.MethodGet, "/legacy/issues/search/gin-gonic/gin/open/router"),
+		newRequest(http.MethodGet, "/repos/julienschmidt/httprouter/issues/comments/1"), // 需要回溯的请求
+	)
+
+	router = newBenchEngine(gplusAPI)
+	assertZeroAllocs(t, router,
+		newRequest(http.MethodGet, "/people/118051310819094153327/activities/123456789"),
+	)
+}
+
+func TestGetValueZeroAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("skipping allocation checks with race detector enabled")
+	}
+	router := newBenchEngine(githubAPI)
+	root := router.routes.Load().trees.get(http.MethodGet)
+	params := make(Params, 0, 10)
+	skippedNodes := make([]skippedNode, 0, 10)
+
+	for _, path := range []string{
+		"/user/repos",
+		"/repos/julienschmidt/httprouter/stargazers",
+		"/users/gin-gonic/events/orgs/gin",
+		"/repos/julienschmidt/httprouter/contents/docs/README.md",
+	} {
+		allocs := testing.AllocsPerRun(100, func() {
+			params, skippedNodes = params[:0], skippedNodes[:0]
+			if value := root.getValue(path, &params, &skippedNodes, false); value.handlers == nil {
+				t.Fatalf("no route found for %s", path)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("%s: got %v allocs per lookup, want 0", path, allocs)
+		}
+	}
+}
+
+func TestParamConstraintsZeroAllocs(t *testing.T) {
+	router := New()
+	router.GET("/users/:id<int>", func(c *Context) {})
+	router.GET("/users/:id<int>/profile", func(c *Context) {})
+	router.GET("/files/:name.:ext", func(c *Context) {})
+	assertZeroAllocs(t, router,
+		newRequest(http.MethodGet, "/users/42"),
+		newRequest(http.MethodGet, "/files/archive.tar.gz"),
+	)
+}
+
+func BenchmarkOneRoute(b *testing.B) {
+	router := New()
+	router.GET("/ping", func(c *Context) {})
+	benchRequest(b, router, newRequest(http.MethodGet, "/ping"))
+}
+
+func BenchmarkOneRouteWithMiddleware(b *testing.B) {
+	router := New()
+	router.Use(func(c *Context) {}, func(c *Context) {}, func(c *Context) {})
+	router.GET("/ping", func(c *Context) {})
+	benchRequest(b, router, newRequest(http.MethodGet, "/ping"))
+}
+
+func BenchmarkParam(b *testing.B) {
+	router := New()
+	router.GET("/user/:name", func(c *Context) {})
+	benchRequest(b, router, newRequest(http.MethodGet, "/user/gordon"))
+}
+
+func BenchmarkParamConstraint(b *testing.B) {
+	router := New()
+	router.GET("/user/:id<int>", func(c *Context) {})
+	benchRequest(b, router, newRequest(http.MethodGet, "/user/42"))
+}
+
+func Benchmark404(b *testing.B) {
+	router := New()
+	router.GET("/something", func(c *Context) {})
+	benchRequest(b, router, newRequest(http.MethodGet, "/ping"))
+}
+
+// GitHub
+
+func BenchmarkGithubStatic(b *testing.B) {
+	benchRequest(b, newBenchEngine(githubAPI), newRequest(http.MethodGet, "/user/repos"))
+}
+
+func BenchmarkGithubParam(b *testing.B) {
+	benchRequest(b, newBenchEngine(githubAPI), newRequest(http.MethodGet, "/repos/julienschmidt/httprouter/stargazers"))
+}
+
+func BenchmarkGithubAll(b *testing.B) {
+	benchRoutes(b, newBenchEngine(githubAPI), githubAPI)
+}
+
+// Parse
+
+func BenchmarkParseStatic(b *testing.B) {
+	benchRequest(b, newBenchEngine(parseAPI), newRequest(http.MethodGet, "/1/users"))
+}
+
+func BenchmarkParseParam(b *testing.B) {
+	benchRequest(b, newBenchEngine(parseAPI), newRequest(http.MethodGet, "/1/classes/go"))
+}
+
+func BenchmarkParse2Params(b *testing.B) {
+	benchRequest(b, newBenchEngine(parseAPI), newRequest(http.MethodGet, "/1/classes/go/123456789"))
+}
+
+func BenchmarkParseAll(b *testing.B) {
+	benchRoutes(b, newBenchEngine(parseAPI), parseAPI)
+}
+
+// Google+
+
+func BenchmarkGPlusStatic(b *testing.B) {
+	benchRequest(b, newBenchEngine(gplusAPI), newRequest(http.MethodGet, "/people"))
+}
+
+func BenchmarkGPlusParam(b *testing.B) {
+	benchRequest(b, newBenchEngine(gplusAPI), newRequest(http.MethodGet, "/people/118051310819094153327"))
+}
+
+func BenchmarkGPlus2Params(b *testing.B) {
+	benchRequest(b, newBenchEngine(gplusAPI), newRequest(http.MethodGet, "/people/118051310819094153327/activities/123456789"))
+}
+
+func BenchmarkGPlusAll(b *testing.B) {
+	benchRoutes(b, newBenchEngine(gplusAPI), gplusAPI)
+}
+
+func BenchmarkParallelGithub(b *testing.B) {
+	router := newBenchEngine(githubAPI)
+	req := newRequest(http.MethodPost, "/repos/manucorporat/sse/git/blobs")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		w := newMockWriter()
+		for pb.Next() {
+			router.ServeHTTP(w, req)
+		}
+	})
+}
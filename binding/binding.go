@@ -0,0 +1,89 @@
+package binding
+
+import "net/http"
+
+// 请求中常见的 Content-Type
+const (
+	MIMEJSON              = "application/json"
+	MIMEHTML              = "text/html"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEPlain             = "text/plain"
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+)
+
+// Binding 接口需要被从请求中读取数据的绑定器实现，例如 JSON 请求体、查询字符串和表单
+type Binding interface {
+	// Name 返回绑定器的名称
+	Name() string
+	// Bind 从请求中读取数据并写入 obj，obj 需要是指针
+	Bind(*http.Request, any) error
+}
+
+// BindingBody 在 Binding 的基础上增加了 BindBody 方法，用于从已经读取出来的请求体中绑定数据
+type BindingBody interface {
+	Binding
+	// BindBody 从请求体的字节切片中读取数据并写入 obj
+	BindBody([]byte, any) error
+}
+
+// BindingUri 接口需要被从路由参数中读取数据的绑定器实现，路由参数不在 http.Request 中，需要单独传入
+type BindingUri interface {
+	// Name 返回绑定器的名称
+	Name() string
+	// BindUri 从路由参数中读取数据并写入 obj
+	BindUri(map[string][]string, any) error
+}
+
+// StructValidator 是绑定完成后校验数据的校验器需要实现的接口
+type StructValidator interface {
+	// ValidateStruct 校验 obj 中的数据，obj 可以是任意类型，不需要校验的类型直接返回 nil
+	ValidateStruct(any) error
+
+	// Engine 返回校验器底层使用的校验引擎
+	Engine() any
+}
+
+// Validator 是所有绑定器在绑定完成后使用的校验器，为 nil 时不进行校验
+// gon 没有内置校验器，可以将其他校验库包装为 StructValidator 后赋值给 Validator
+var Validator StructValidator
+
+// 各种绑定器的实例，实现了 Binding、BindingBody 或 BindingUri 接口，可以在 Context.ShouldBindWith 等方法中使用
+var (
+	JSON          BindingBody = jsonBinding{}
+	XML           BindingBody = xmlBinding{}
+	Form          Binding     = formBinding{}
+	Query         Binding     = queryBinding{}
+	FormPost      Binding     = formPostBinding{}
+	FormMultipart Binding     = formMultipartBinding{}
+	Uri           BindingUri  = uriBinding{}
+	Header        Binding     = headerBinding{}
+)
+
+// Default 根据请求方式和 Content-Type 返回默认的绑定器
+// GET 请求总是使用 Form 绑定查询字符串，其他请求根据 Content-Type 选择 JSON、XML 或表单绑定器
+func Default(method, contentType string) Binding {
+	if method == http.MethodGet {
+		return Form
+	}
+
+	switch contentType {
+	case MIMEJSON:
+		return JSON
+	case MIMEXML, MIMEXML2:
+		return XML
+	case MIMEMultipartPOSTForm:
+		return FormMultipart
+	default: // 包括 MIMEPOSTForm
+		return Form
+	}
+}
+
+// validate 使用 Validator 校验 obj，Validator 为 nil 时直接返回 nil
+func validate(obj any) error {
+	if Validator == nil {
+		return nil
+	}
+	return Validator.ValidateStruct(obj)
+}
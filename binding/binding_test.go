@@ -0,0 +1,334 @@
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fooStruct struct {
+	Foo string `json:"foo" xml:"foo" form:"foo" uri:"foo" header:"foo"`
+}
+
+type fooBarStruct struct {
+	fooStruct `json:"-"`
+	Bar       string `json:"bar" form:"bar"`
+}
+
+type formStruct struct {
+	Name     string        `form:"name"`
+	Age      int           `form:"age,default=18"`
+	Admin    bool          `form:"admin"`
+	Score    float64       `form:"score"`
+	Tags     []string      `form:"tags"`
+	Pair     [2]int        `form:"pair"`
+	Timeout  time.Duration `form:"timeout"`
+	Birthday time.Time     `form:"birthday" time_format:"2006-01-02" time_utc:"1"`
+	Created  time.Time     `form:"created" time_format:"unix"`
+	Ptr      *int          `form:"ptr"`
+	Missing  *int          `form:"missing"`
+	Ignored  string        `form:"-"`
+	Meta     map[string]int
+	private  string
+}
+
+func TestDefault(t *testing.T) {
+	for _, tt := range []struct {
+		method, contentType string
+		want                Binding
+	}{
+		{http.MethodGet, "", Form},
+		{http.MethodGet, MIMEJSON, Form},
+		{http.MethodPost, MIMEJSON, JSON},
+		{http.MethodPut, MIMEXML, XML},
+		{http.MethodPatch, MIMEXML2, XML},
+		{http.MethodPost, MIMEPOSTForm, Form},
+		{http.MethodPost, MIMEMultipartPOSTForm, FormMultipart},
+		{http.MethodDelete, "", Form},
+	} {
+		if got := Default(tt.method, tt.contentType); got != tt.want {
+			t.Errorf("Default(%s, %q) = %s, want %s", tt.method, tt.contentType, got.Name(), tt.want.Name())
+		}
+	}
+}
+
+func TestBindingJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"foo": "bar"}`))
+	var obj fooStruct
+	if err := JSON.Bind(req, &obj); err != nil || obj.Foo != "bar" {
+		t.Errorf("Bind = %v, %+v", err, obj)
+	}
+
+	obj = fooStruct{}
+	if err := JSON.BindBody([]byte(`{"foo": "baz"}`), &obj); err != nil || obj.Foo != "baz" {
+		t.Errorf("BindBody = %v, %+v", err, obj)
+	}
+
+	if err := JSON.BindBody([]byte(`{"foo": `), &obj); err == nil {
+		t.Error("BindBody with invalid JSON did not fail")
+	}
+	if err := JSON.Bind(&http.Request{}, &obj); err == nil {
+		t.Error("Bind without body did not fail")
+	}
+}
+
+func TestBindingJSONDecoderOptions(t *testing.T) {
+	defer func() { EnableDecoderUseNumber, EnableDecoderDisallowUnknownFields = false, false }()
+
+	EnableDecoderUseNumber = true
+	var m map[string]any
+	if err := JSON.BindBody([]byte(`{"n": 12345678901234567890}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["n"].(interface{ String() string }); !ok {
+		t.Errorf("number decoded as %T, want json.Number", m["n"])
+	}
+
+	EnableDecoderDisallowUnknownFields = true
+	var obj fooStruct
+	if err := JSON.BindBody([]byte(`{"foo": "bar", "what": "unknown"}`), &obj); err == nil {
+		t.Error("unknown field did not fail")
+	}
+}
+
+func TestBindingXML(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<?xml version="1.0"?><root><foo>bar</foo></root>`))
+	var obj fooStruct
+	if err := XML.Bind(req, &obj); err != nil || obj.Foo != "bar" {
+		t.Errorf("Bind = %v, %+v", err, obj)
+	}
+
+	if err := XML.BindBody([]byte(`<root><foo>`), &obj); err == nil {
+		t.Error("BindBody with invalid XML did not fail")
+	}
+	if err := XML.Bind(&http.Request{}, &obj); err == nil {
+		t.Error("Bind without body did not fail")
+	}
+}
+
+func TestBindingFormWithoutBody(t *testing.T) {
+	// 请求体为空的 POST 请求返回错误而不是触发 panic
+	for _, b := range []Binding{Form, FormPost} {
+		req := &http.Request{Method: http.MethodPost, Header: http.Header{"Content-Type": {MIMEPOSTForm}}}
+		var obj fooStruct
+		if err := b.Bind(req, &obj); err == nil {
+			t.Errorf("%s: Bind without body did not fail", b.Name())
+		}
+	}
+}
+
+func TestBindingForm(t *testing.T) {
+	body := "name=gon&admin=true&score=9.5&tags=a&tags=b&pair=1&pair=2&timeout=1s&birthday=2020-01-02&created=1600000000&ptr=7&Meta={\"a\":1}&Ignored=x&private=x"
+	req := httptest.NewRequest(http.MethodPost, "/?name=query&age=20", strings.NewReader(body))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+
+	var obj formStruct
+	if err := Form.Bind(req, &obj); err != nil {
+		t.Fatal(err)
+	}
+
+	seven := 7
+	want := formStruct{
+		Name:     "gon", // 请求体中的值排在查询字符串前面
+		Age:      20,
+		Admin:    true,
+		Score:    9.5,
+		Tags:     []string{"a", "b"},
+		Pair:     [2]int{1, 2},
+		Timeout:  time.Second,
+		Birthday: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Created:  time.Unix(1600000000, 0),
+		Ptr:      &seven,
+		Meta:     map[string]int{"a": 1},
+	}
+	if !reflect.DeepEqual(obj, want) {
+		t.Errorf("got %+v\nwant %+v", obj, want)
+	}
+}
+
+func TestBindingFormDefaultValue(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?name=gon", nil)
+	var obj formStruct
+	if err := Form.Bind(req, &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Name != "gon" || obj.Age != 18 || obj.Missing != nil {
+		t.Errorf("got %+v", obj)
+	}
+}
+
+func TestBindingFormInvalidValue(t *testing.T) {
+	for _, query := range []string{"age=abc", "admin=maybe", "score=x", "pair=1", "timeout=forever", "birthday=yesterday"} {
+		var obj formStruct
+		if err := Form.Bind(httptest.NewRequest(http.MethodGet, "/?"+query, nil), &obj); err == nil {
+			t.Errorf("%s: Bind did not fail", query)
+		}
+	}
+}
+
+func TestBindingFormPost(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/?foo=query&bar=query", strings.NewReader("foo=body"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+
+	var obj fooBarStruct
+	if err := FormPost.Bind(req, &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Foo != "body" || obj.Bar != "" {
+		t.Errorf("got %+v, want only values from the body", obj)
+	}
+}
+
+func TestBindingFormMultipart(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	_ = mw.WriteField("foo", "bar")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		w, _ := mw.CreateFormFile("files", name)
+		_, _ = w.Write([]byte(name))
+	}
+	w, _ := mw.CreateFormFile("file", "c.txt")
+	_, _ = w.Write([]byte("c"))
+	_ = mw.Close()
+	data := body.Bytes()
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var obj struct {
+		Foo   string                  `form:"foo"`
+		File  *multipart.FileHeader   `form:"file"`
+		Files []*multipart.FileHeader `form:"files"`
+		Pair  [2]multipart.FileHeader `form:"files"`
+	}
+	if err := FormMultipart.Bind(req, &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Foo != "bar" || obj.File == nil || obj.File.Filename != "c.txt" {
+		t.Errorf("got %+v", obj)
+	}
+	if len(obj.Files) != 2 || obj.Files[0].Filename != "a.txt" || obj.Pair[1].Filename != "b.txt" {
+		t.Errorf("got files %+v", obj.Files)
+	}
+
+	var invalid struct {
+		File string `form:"file"`
+	}
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if err := FormMultipart.Bind(req, &invalid); !errors.Is(err, ErrMultiFileHeader) {
+		t.Errorf("got %v, want ErrMultiFileHeader", err)
+	}
+}
+
+func TestBindingQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/?foo=bar", strings.NewReader("foo=body"))
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	var obj fooStruct
+	if err := Query.Bind(req, &obj); err != nil || obj.Foo != "bar" {
+		t.Errorf("Bind = %v, %+v", err, obj)
+	}
+}
+
+func TestBindingHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Foo", "bar")
+	req.Header.Set("X-Request-Id", "42")
+
+	var obj struct {
+		Foo   string `header:"foo"`
+		ID    int    `header:"x-request-id"`
+		Limit int    `header:"limit,default=10"`
+	}
+	if err := Header.Bind(req, &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Foo != "bar" || obj.ID != 42 || obj.Limit != 10 {
+		t.Errorf("got %+v", obj)
+	}
+}
+
+func TestBindingUri(t *testing.T) {
+	var obj struct {
+		ID   int    `uri:"id"`
+		Name string `uri:"name"`
+	}
+	if err := Uri.BindUri(map[string][]string{"id": {"42"}, "name": {"gon"}}, &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.ID != 42 || obj.Name != "gon" {
+		t.Errorf("got %+v", obj)
+	}
+	if err := Uri.BindUri(map[string][]string{"id": {"x"}}, &obj); err == nil {
+		t.Error("BindUri with invalid value did not fail")
+	}
+}
+
+func TestMapFormToMap(t *testing.T) {
+	form := map[string][]string{"a": {"1", "2"}, "b": {"3"}}
+
+	m := map[string]string{}
+	if err := mapForm(&m, form); err != nil || !reflect.DeepEqual(m, map[string]string{"a": "2", "b": "3"}) {
+		t.Errorf("map[string]string = %v, %v", m, err)
+	}
+
+	ms := map[string][]string{}
+	if err := mapForm(ms, form); err != nil || !reflect.DeepEqual(ms, form) {
+		t.Errorf("map[string][]string = %v, %v", ms, err)
+	}
+
+	mi := map[string]int{}
+	if err := mapForm(mi, form); !errors.Is(err, ErrConvertToMapString) {
+		t.Errorf("map[string]int: got %v, want ErrConvertToMapString", err)
+	}
+}
+
+// customID 实现了 BindUnmarshaler 接口
+type customID string
+
+func (id *customID) UnmarshalParam(param string) error {
+	*id = customID("id-" + param)
+	return nil
+}
+
+func TestBindUnmarshaler(t *testing.T) {
+	var obj struct {
+		ID customID `form:"id"`
+	}
+	if err := mapForm(&obj, map[string][]string{"id": {"42"}}); err != nil || obj.ID != "id-42" {
+		t.Errorf("got %+v, %v", obj, err)
+	}
+}
+
+// testValidator 拒绝 Foo 为空的 fooStruct
+type testValidator struct{}
+
+func (testValidator) ValidateStruct(obj any) error {
+	if foo, ok := obj.(*fooStruct); ok && foo.Foo == "" {
+		return errors.New("foo is required")
+	}
+	return nil
+}
+
+func (testValidator) Engine() any {
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	Validator = testValidator{}
+	defer func() { Validator = nil }()
+
+	var obj fooStruct
+	if err := JSON.BindBody([]byte(`{}`), &obj); err == nil {
+		t.Error("validator was not called")
+	}
+	if err := Query.Bind(httptest.NewRequest(http.MethodGet, "/?foo=bar", nil), &obj); err != nil {
+		t.Errorf("valid object failed validation: %v", err)
+	}
+}
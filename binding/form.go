@@ -0,0 +1,69 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+)
+
+// defaultMemory 是解析 multipart/form-data 请求体时使用的最大内存，超出的部分会存储到临时文件中
+const defaultMemory = 32 << 20
+
+// formBinding 从查询字符串和请求体中的表单绑定数据，请求体可以是 urlencoded 或 multipart 表单
+type formBinding struct{}
+
+// formPostBinding 只从 urlencoded 请求体中绑定数据
+type formPostBinding struct{}
+
+// formMultipartBinding 从 multipart 请求体中绑定数据，支持绑定上传的文件
+type formMultipartBinding struct{}
+
+// Name 实现了 Binding 接口
+func (formBinding) Name() string {
+	return "form"
+}
+
+// Bind 实现了 Binding 接口，查询字符串和请求体中存在同名参数时，请求体中的值排在前面
+func (formBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	if err := mapForm(obj, req.Form); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// Name 实现了 Binding 接口
+func (formPostBinding) Name() string {
+	return "form-urlencoded"
+}
+
+// Bind 实现了 Binding 接口
+func (formPostBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	if err := mapForm(obj, req.PostForm); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// Name 实现了 Binding 接口
+func (formMultipartBinding) Name() string {
+	return "multipart/form-data"
+}
+
+// Bind 实现了 Binding 接口，类型为 *multipart.FileHeader 或 []*multipart.FileHeader 的字段会绑定上传的文件
+func (formMultipartBinding) Bind(req *http.Request, obj any) error {
+	if err := req.ParseMultipartForm(defaultMemory); err != nil {
+		return err
+	}
+	if err := mappingByPtr(obj, (*multipartRequest)(req), "form"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
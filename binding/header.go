@@ -0,0 +1,38 @@
+package binding
+
+import (
+	"net/http"
+	"net/textproto"
+	"reflect"
+)
+
+// headerBinding 从请求头中绑定数据，使用 header 标签指定请求头名称
+type headerBinding struct{}
+
+// Name 实现了 Binding 接口
+func (headerBinding) Name() string {
+	return "header"
+}
+
+// Bind 实现了 Binding 接口
+func (headerBinding) Bind(req *http.Request, obj any) error {
+	if err := mapHeader(obj, req.Header); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// mapHeader 将请求头 h 中的值写入 ptr 指向的结构体
+func mapHeader(ptr any, h map[string][]string) error {
+	return mappingByPtr(ptr, headerSource(h), "header")
+}
+
+// headerSource 是以请求头为数据来源的 setter
+type headerSource map[string][]string
+
+var _ setter = headerSource(nil)
+
+// TrySet 实现了 setter 接口，请求头名称不区分大小写，查找前会转换为规范格式
+func (hs headerSource) TrySet(value reflect.Value, field reflect.StructField, tagValue string, opt setOptions) (bool, error) {
+	return setByForm(value, field, hs, textproto.CanonicalMIMEHeaderKey(tagValue), opt)
+}
@@ -0,0 +1,53 @@
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// EnableDecoderUseNumber 为 true 时，JSON 绑定器会调用 json.Decoder 的 UseNumber 方法
+// 将数字解析为 json.Number 而不是 float64
+var EnableDecoderUseNumber = false
+
+// EnableDecoderDisallowUnknownFields 为 true 时，JSON 绑定器会调用 json.Decoder 的 DisallowUnknownFields 方法
+// 请求体中存在目标结构体中没有的字段时返回错误
+var EnableDecoderDisallowUnknownFields = false
+
+// jsonBinding 从 JSON 请求体中绑定数据
+type jsonBinding struct{}
+
+// Name 实现了 Binding 接口
+func (jsonBinding) Name() string {
+	return "json"
+}
+
+// Bind 实现了 Binding 接口，从请求体中解析 JSON
+func (jsonBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodeJSON(req.Body, obj)
+}
+
+// BindBody 实现了 BindingBody 接口，从 body 中解析 JSON
+func (jsonBinding) BindBody(body []byte, obj any) error {
+	return decodeJSON(bytes.NewReader(body), obj)
+}
+
+// decodeJSON 从 r 中解析 JSON 写入 obj，并使用 Validator 校验
+func decodeJSON(r io.Reader, obj any) error {
+	decoder := json.NewDecoder(r)
+	if EnableDecoderUseNumber {
+		decoder.UseNumber()
+	}
+	if EnableDecoderDisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
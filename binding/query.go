@@ -0,0 +1,20 @@
+package binding
+
+import "net/http"
+
+// queryBinding 只从查询字符串中绑定数据
+type queryBinding struct{}
+
+// Name 实现了 Binding 接口
+func (queryBinding) Name() string {
+	return "query"
+}
+
+// Bind 实现了 Binding 接口
+func (queryBinding) Bind(req *http.Request, obj any) error {
+	values := req.URL.Query()
+	if err := mapForm(obj, values); err != nil {
+		return err
+	}
+	return validate(obj)
+}
@@ -0,0 +1,17 @@
+package binding
+
+// uriBinding 从路由参数中绑定数据，使用 uri 标签指定参数名称
+type uriBinding struct{}
+
+// Name 实现了 BindingUri 接口
+func (uriBinding) Name() string {
+	return "uri"
+}
+
+// BindUri 实现了 BindingUri 接口
+func (uriBinding) BindUri(m map[string][]string, obj any) error {
+	if err := mapURI(obj, m); err != nil {
+		return err
+	}
+	return validate(obj)
+}
@@ -0,0 +1,39 @@
+package binding
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// xmlBinding 从 XML 请求体中绑定数据
+type xmlBinding struct{}
+
+// Name 实现了 Binding 接口
+func (xmlBinding) Name() string {
+	return "xml"
+}
+
+// Bind 实现了 Binding 接口，从请求体中解析 XML
+func (xmlBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodeXML(req.Body, obj)
+}
+
+// BindBody 实现了 BindingBody 接口，从 body 中解析 XML
+func (xmlBinding) BindBody(body []byte, obj any) error {
+	return decodeXML(bytes.NewReader(body), obj)
+}
+
+// decodeXML 从 r 中解析 XML 写入 obj，并使用 Validator 校验
+func decodeXML(r io.Reader, obj any) error {
+	decoder := xml.NewDecoder(r)
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
@@ -0,0 +1,190 @@
+package gon
+
+import (
+	"regexp"
+	"strings"
+)
+
+// regParamTypeName 用于判断约束是否是一个参数类型名称，不是类型名称的约束会被当作正则表达式处理
+var regParamTypeName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// defaultParamTypes 定义了 Engine 默认支持的参数类型，可以在路由中通过 ":name<type>" 使用
+var defaultParamTypes = map[string]func(string) bool{
+	"int":   isInt,
+	"uint":  isUint,
+	"alpha": isAlpha,
+	"alnum": isAlnum,
+	"uuid":  isUUID,
+}
+
+// RegisterParamType 用于注册一个参数类型，注册后可以在路由中通过 ":name<type>" 约束参数的取值
+// 类型名称只能由字母、数字和下划线组成，且不能以数字开头，注册同名类型会覆盖已有的类型（包括默认类型）
+// 参数类型需要在使用它的路由注册之前注册，已经注册的路由会继续使用注册时的匹配函数
+// 同一位置可以注册约束不同的参数，例如 "/users/:id<int>" 和 "/users/:slug<alpha>"，约束相同的参数会触发 ConflictWildcard 的 panic
+// 查找时按照注册顺序依次尝试同一位置的参数，没有约束的参数最后尝试，约束不满足时会回溯到下一个参数或者静态路由等其他分支
+//
+//	engine.RegisterParamType("even", func(v string) bool {
+//	    n, err := strconv.Atoi(v)
+//	    return err == nil && n%2 == 0
+//	})
+//	engine.GET("/numbers/:n<even>", handler)
+func (engine *Engine) RegisterParamType(name string, match func(value string) bool) {
+	assert1(regParamTypeName.MatchString(name), "invalid param type name '"+name+"'")
+	assert1(match != nil, "param type '"+name+"' must have a match function")
+
+	engine.routesMu.Lock()
+	defer engine.routesMu.Unlock()
+	engine.paramTypes[name] = match
+	// 类型发生变化，清空已经解析过的约束
+	clear(engine.constraints)
+}
+
+// paramMatcher 返回约束对应的匹配函数，必须在持有 routesMu 时调用
+// 约束为参数类型名称时返回注册的匹配函数，否则将约束作为正则表达式编译，正则表达式需要匹配完整的参数值
+// 约束不合法时会触发 panic
+func (engine *Engine) paramMatcher(constraint string) func(string) bool {
+	if match, ok := engine.constraints[constraint]; ok {
+		return match
+	}
+
+	var match func(string) bool
+	if regParamTypeName.MatchString(constraint) {
+		match = engine.paramTypes[constraint]
+		assert1(match != nil, "unknown param type '"+constraint+"'")
+	} else {
+		reg, err := regexp.Compile(`^(?:` + constraint + `)$`)
+		if err != nil {
+			panic("invalid param constraint '" + constraint + "': " + err.Error())
+		}
+		match = reg.MatchString
+	}
+
+	engine.constraints[constraint] = match
+	return match
+}
+
+// compileConstraints 解析路径中所有的参数约束，约束不合法时会在路由插入路由树之前触发 panic
+func (engine *Engine) compileConstraints(path string) {
+	for {
+		wildcard, i, _ := findWildCard(path)
+		if i < 0 {
+			return
+		}
+		if constraint := wildcardConstraint(wildcard); constraint != "" {
+			engine.paramMatcher(constraint)
+		}
+		path = path[i+len(wildcard):]
+	}
+}
+
+// resolvePath 为路由 path 经过的带约束但还未设置匹配函数的参数节点设置匹配函数，只会访问 path 经过的节点
+func (n *node) resolvePath(path string, resolve func(string) func(string) bool) {
+	n.walkPath(path, func(n *node) {
+		if n.nType == param && n.match == nil {
+			if constraint := wildcardConstraint(n.path); constraint != "" {
+				n.match = resolve(constraint)
+			}
+		}
+	})
+}
+
+// copyConstraints 将路由树 from 中路由 path 经过的参数节点的匹配函数复制到当前路由树中对应的参数节点上
+// 删除路由后会使用剩余的路由重新构建路由树，复制匹配函数可以保证剩余路由的约束不会被之后注册的同名参数类型改变
+func (n *node) copyConstraints(from *node, path string) {
+	var matches []func(string) bool
+	from.walkPath(path, func(n *node) {
+		if n.nType == param {
+			matches = append(matches, n.match)
+		}
+	})
+	n.walkPath(path, func(n *node) {
+		if n.nType == param {
+			n.match, matches = matches[0], matches[1:]
+		}
+	})
+}
+
+// wildcardName 返回通配符的参数名，例如 ":id<int>" 返回 "id"，"*filepath" 返回 "filepath"
+func wildcardName(wildcard string) string {
+	name := wildcard[1:]
+	if i := strings.IndexByte(name, '<'); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// wildcardConstraint 返回通配符的约束，例如 ":id<int>" 返回 "int"，没有约束时返回空字符串
+func wildcardConstraint(wildcard string) string {
+	i := strings.IndexByte(wildcard, '<')
+	if i < 0 || wildcard[len(wildcard)-1] != '>' {
+		return ""
+	}
+	return wildcard[i+1 : len(wildcard)-1]
+}
+
+// isInt 判断参数值是否是一个十进制整数，允许以 '-' 开头
+func isInt(s string) bool {
+	if len(s) > 1 && s[0] == '-' {
+		s = s[1:]
+	}
+	return isUint(s)
+}
+
+// isUint 判断参数值是否是一个十进制非负整数
+func isUint(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlpha 判断参数值是否只包含 ASCII 字母
+func isAlpha(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlnum 判断参数值是否只包含 ASCII 字母和数字
+func isAlnum(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c|0x20 < 'a' || c|0x20 > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID 判断参数值是否是 8-4-4-4-12 格式的 UUID，不区分大小写
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if c := s[i]; (c < '0' || c > '9') && (c|0x20 < 'a' || c|0x20 > 'f') {
+				return false
+			}
+		}
+	}
+	return true
+}
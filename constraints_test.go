@@ -0,0 +1,142 @@
+package gon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestConstrainedParamSiblings(t *testing.T) {
+	// 约束相同（包括都没有约束）的参数以及 catch-all 不能和参数共存
+	for _, routes := range [][]string{
+		{"/users/:id<int>", "/users/:num<int>"},
+		{"/users/:id", "/users/:name"},
+		{"/users/:id<int>", "/users/:id<int>x"},
+		{"/files/*filepath", "/files/:name<int>"},
+	} {
+		router := New()
+		router.GET(routes[0], func(c *Context) {})
+		recv := catchPanic(func() {
+			router.GET(routes[1], func(c *Context) {})
+		})
+
+		var conflict *RouteConflictError
+		if err, ok := recv.(error); !ok || !errors.As(err, &conflict) {
+			t.Errorf("%v: got %v, want a route conflict", routes, recv)
+		}
+	}
+
+	// 约束不同的参数可以共存，按照注册顺序依次尝试，没有约束的参数最后尝试，约束不满足或者之后的路径不匹配时回溯到下一个参数
+	router := New()
+	handler := func(c *Context) {
+		c.Writer.Header().Set("X-Full-Path", c.FullPath())
+		c.Writer.Header().Set("X-Params", fmt.Sprint(c.Params))
+	}
+	router.GET("/users/:name", handler)
+	router.GET("/users/:id<int>", handler)
+	router.GET("/users/:slug<alpha>", handler)
+	router.GET("/users/me", handler)
+	router.GET("/users/:id<int>/posts", handler)
+	router.GET("/users/:name/settings", handler)
+	router.GET("/numbers/:u<uint>", handler)
+	router.GET("/numbers/:i<int>", handler)
+
+	for _, tt := range []struct {
+		path     string
+		fullPath string
+		params   string
+	}{
+		{"/users/42", "/users/:id<int>", "[{id 42}]"},
+		{"/users/bob", "/users/:slug<alpha>", "[{slug bob}]"},
+		{"/users/bob-1", "/users/:name", "[{name bob-1}]"},
+		{"/users/me", "/users/me", "[]"},
+		{"/users/42/posts", "/users/:id<int>/posts", "[{id 42}]"},
+		{"/users/42/settings", "/users/:name/settings", "[{name 42}]"},
+		{"/numbers/5", "/numbers/:u<uint>", "[{u 5}]"},
+		{"/numbers/-5", "/numbers/:i<int>", "[{i -5}]"},
+	} {
+		w := performRequest(router, http.MethodGet, tt.path)
+		if w.Code != http.StatusOK || w.Header().Get("X-Full-Path") != tt.fullPath || w.Header().Get("X-Params") != tt.params {
+			t.Errorf("GET %s: got %d %q %s, want %q %s", tt.path, w.Code,
+				w.Header().Get("X-Full-Path"), w.Header().Get("X-Params"), tt.fullPath, tt.params)
+		}
+	}
+	if w := performRequest(router, http.MethodGet, "/users/bob/posts"); w.Code != http.StatusNotFound {
+		t.Errorf("GET /users/bob/posts: got %d, want 404", w.Code)
+	}
+
+	// 大小写不敏感的路径修正同样会依次尝试参数
+	router.RedirectFixedPath = true
+	if w := performRequest(router, http.MethodGet, "/USERS/42/Settings"); w.Code != http.StatusMovedPermanently ||
+		w.Header().Get("Location") != "/users/42/settings" {
+		t.Errorf("GET /USERS/42/Settings: got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	// 删除一个参数路由后其他参数路由不受影响
+	router.RemoveRoute(http.MethodGet, "/users/:id<int>")
+	if w := performRequest(router, http.MethodGet, "/users/42"); w.Header().Get("X-Full-Path") != "/users/:name" {
+		t.Errorf("GET /users/42 after removing /users/:id<int>: got %d %q", w.Code, w.Header().Get("X-Full-Path"))
+	}
+	if w := performRequest(router, http.MethodGet, "/users/42/posts"); w.Header().Get("X-Full-Path") != "/users/:id<int>/posts" {
+		t.Errorf("GET /users/42/posts after removing /users/:id<int>: got %d %q", w.Code, w.Header().Get("X-Full-Path"))
+	}
+}
+
+func TestRegisterParamType(t *testing.T) {
+	router := New()
+	router.RegisterParamType("even", func(v string) bool {
+		return isUint(v) && (v[len(v)-1]-'0')%2 == 0
+	})
+	router.GET("/even/:n<even>", func(c *Context) {})
+	router.GET("/odd/:n<int>", func(c *Context) {})
+
+	// 重新注册同名类型不会改变已经注册的路由，删除其他路由重新构建路由树后也不会改变
+	router.RegisterParamType("even", func(v string) bool { return true })
+	router.RemoveRoute(http.MethodGet, "/odd/:n<int>")
+	for path, code := range map[string]int{
+		"/even/42": http.StatusOK,
+		"/even/43": http.StatusNotFound,
+	} {
+		if w := performRequest(router, http.MethodGet, path); w.Code != code {
+			t.Errorf("GET %s: got %d, want %d", path, w.Code, code)
+		}
+	}
+
+	// 之后注册的路由使用新的类型
+	router.GET("/any/:n<even>", func(c *Context) {})
+	if w := performRequest(router, http.MethodGet, "/any/43"); w.Code != http.StatusOK {
+		t.Errorf("GET /any/43: got %d, want 200", w.Code)
+	}
+
+	for _, name := range []string{"", "1abc", "a-b"} {
+		if catchPanic(func() { router.RegisterParamType(name, isInt) }) == nil {
+			t.Errorf("RegisterParamType(%q) did not panic", name)
+		}
+	}
+	if catchPanic(func() { router.RegisterParamType("nil", nil) }) == nil {
+		t.Error("RegisterParamType with nil match did not panic")
+	}
+}
+
+// TestRegisterParamTypeConcurrent 在注册路由的同时注册参数类型，需要使用 -race 运行才能发现数据竞争
+func TestRegisterParamTypeConcurrent(t *testing.T) {
+	router := New()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			router.RegisterParamType("hex", func(v string) bool { return v != "" })
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, path := range []string{"/a/:id<int>", "/b/:id<uuid>", "/c/:id<[a-z]+>"} {
+			router.GET(path, func(c *Context) {})
+			router.ValidateRoutes([]RouteSpec{{Method: http.MethodGet, Path: path + "/x"}})
+		}
+	}()
+	wg.Wait()
+}
@@ -1,24 +1,765 @@
 package gon
 
 import (
+	"context"
+	"errors"
+	"io"
 	"math"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stolenzc/gon/binding"
+	"github.com/stolenzc/gon/render"
 )
 
+// ContextKey 是 Context.Value 返回 Context 自身时使用的 key
+const ContextKey = "_stolenzc/gon/contextkey"
+
+// BodyBytesKey 是 ShouldBindBodyWith 在 Keys 中保存请求体时使用的 key
+const BodyBytesKey = "_stolenzc/gon/bodybyteskey"
+
+// ContextKeyType 是 ContextRequestKey 的类型，避免和其他包中的 key 冲突
+type ContextKeyType int
+
+// ContextRequestKey 是 Context.Value 返回 c.Request 时使用的 key
+const ContextRequestKey ContextKeyType = 0
+
 // abortIndex 表示中止函数中使用的典型值，其值为 127
 // 会使用该值限制一个请求中的处理链函数的数量
 const abortIndex int8 = math.MaxInt8 >> 1
 
 // Context 用来存储请求上下文信息， 每个请求都会有一个独立的 Context 实例
 type Context struct {
-	Request  *http.Request       // HTTP 请求对象
-	Writer   http.ResponseWriter // HTTP 可写入的响应
-	handlers HandlerChain        // 当前请求的处理链
-	index    int8                // 当前处理的中间件索引
-	engine   *Engine             // 指回向入口 Engine
-	params   *Params             // URL 参数列表，存储请求的 URL 参数
+	writermem    responseWriter // 默认的响应写入器，随 Context 一起复用，避免内存分配
+	Request      *http.Request  // HTTP 请求对象
+	Writer       ResponseWriter // HTTP 可写入的响应
+	Params       Params         // 匹配到的 URL 参数
+	handlers     HandlerChain   // 当前请求的处理链
+	index        int8           // 当前处理的中间件索引
+	fullPath     string         // 匹配到的路由的完整路径模板，例如 "/users/:id"
+	engine       *Engine        // 指回向入口 Engine
+	params       *Params        // URL 参数列表，存储请求的 URL 参数
+	skippedNodes *[]skippedNode // 路由查找时跳过的节点，用于回溯，容量由路由表的 maxSections 决定
+	queryCache   url.Values     // 缓存 c.Request.URL.Query() 的解析结果
+	formCache    url.Values     // 缓存 c.Request.PostForm 的解析结果，包含 POST、PATCH 和 PUT 请求体中的表单参数
+
+	mu       sync.RWMutex // 读写锁，用于保护 Keys 的并发访问
+	released atomic.Bool  // 调试模式下请求结束后设置为 true，用于检测请求结束后继续使用 Context 的情况
+
+	// Keys 是每个请求独有的键值对，通过 Set 和 Get 访问
+	Keys map[string]any
+
+	// Errors 记录了处理链中所有处理函数和中间件产生的错误
+	Errors errorMsgs
+}
+
+/************************************/
+/********** CONTEXT CREATION ********/
+/************************************/
+
+// reset 用于在 Context 从 engine.pool 中取出复用时重置 Context 的状态
+func (c *Context) reset() {
+	c.Writer = &c.writermem
+	c.Params = c.Params[:0]
+	c.handlers = nil
+	c.index = -1
+
+	c.fullPath = ""
+	c.Errors = c.Errors[:0]
+	c.Keys = nil
+	c.queryCache = nil
+	c.formCache = nil
+	*c.params = (*c.params)[:0]
+	*c.skippedNodes = (*c.skippedNodes)[:0]
+}
+
+// Copy 返回当前 Context 的只读副本，包含 Request、Params、Keys 和 FullPath
+// 副本的处理链为空且已经被中止，也不能写入响应，在处理函数中启动的 goroutine 需要使用副本而不是 c 本身
+// 因为请求结束后 c 会被放回 engine.pool，供其他请求复用
+//
+//	router.GET("/async", func(c *gon.Context) {
+//	    cc := c.Copy()
+//	    go func() {
+//	        log.Println("done! in path " + cc.Request.URL.Path)
+//	    }()
+//	})
+func (c *Context) Copy() *Context {
+	c.checkReleased()
+	cp := Context{
+		writermem: c.writermem,
+		Request:   c.Request,
+		engine:    c.engine,
+	}
+	cp.writermem.ResponseWriter = nil
+	cp.Writer = &cp.writermem
+	cp.index = abortIndex
+	cp.handlers = nil
+	cp.fullPath = c.fullPath
+
+	c.mu.RLock()
+	cp.Keys = make(map[string]any, len(c.Keys))
+	for k, v := range c.Keys {
+		cp.Keys[k] = v
+	}
+	c.mu.RUnlock()
+
+	cp.Params = make(Params, len(c.Params))
+	copy(cp.Params, c.Params)
+	return &cp
+}
+
+// release 在调试模式下将 Context 标记为已释放，之后调用 Context 的方法会触发 panic
+func (c *Context) release() {
+	c.released.Store(true)
+}
+
+// checkReleased 检查 Context 是否已经被释放，已释放时触发 panic
+// 只有调试模式下请求结束的 Context 会被标记为已释放，非调试模式下只有一次原子读取的开销
+func (c *Context) checkReleased() {
+	if c.released.Load() {
+		panic("gon: Context used after the request has finished, use c.Copy() to pass the Context to goroutines")
+	}
+}
+
+// FullPath 返回匹配到的路由的完整路径模板，没有匹配到路由时返回空字符串
+//
+//	router.GET("/user/:id", func(c *gon.Context) {
+//	    c.FullPath() == "/user/:id" // true
+//	})
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
+// HandlerName 返回路由处理函数的名称，例如处理函数为 handleGetUsers() 时，返回 "main.handleGetUsers"
+func (c *Context) HandlerName() string {
+	return nameOfFunction(c.handlers.Last())
+}
+
+// HandlerNames 按照处理链的顺序返回所有处理函数的名称
+func (c *Context) HandlerNames() []string {
+	hn := make([]string, 0, len(c.handlers))
+	for _, val := range c.handlers {
+		hn = append(hn, nameOfFunction(val))
+	}
+	return hn
+}
+
+// Handler 返回路由的处理函数
+func (c *Context) Handler() HandlerFunc {
+	return c.handlers.Last()
+}
+
+/************************************/
+/******** METADATA MANAGEMENT********/
+/************************************/
+
+// Set 在当前 Context 中保存一个键值对，Keys 为 nil 时会先初始化
+// 使用 c.mu 加锁，可以在处理函数启动的 goroutine 中并发调用
+func (c *Context) Set(key string, value any) {
+	c.checkReleased()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Keys == nil {
+		c.Keys = make(map[string]any)
+	}
+
+	c.Keys[key] = value
+}
+
+// Get 返回 key 对应的值，以及 key 是否存在
+func (c *Context) Get(key string) (value any, exists bool) {
+	c.checkReleased()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, exists = c.Keys[key]
+	return
+}
+
+// MustGet 返回 key 对应的值，key 不存在时触发 panic
+func (c *Context) MustGet(key string) any {
+	if value, exists := c.Get(key); exists {
+		return value
+	}
+	panic("Key \"" + key + "\" does not exist")
+}
+
+// Value 返回 c 中 key 对应的值，并转换为类型 T，key 不存在或值的类型不是 T 时返回 T 的零值和 false
+//
+//	c.Set("user", user)
+//	u, ok := gon.Value[*User](c, "user")
+func Value[T any](c *Context, key string) (value T, ok bool) {
+	if v, exists := c.Get(key); exists {
+		value, ok = v.(T)
+	}
+	return
+}
+
+// GetString 返回 key 对应的字符串值
+func (c *Context) GetString(key string) (s string) {
+	s, _ = Value[string](c, key)
+	return
+}
+
+// GetBool 返回 key 对应的布尔值
+func (c *Context) GetBool(key string) (b bool) {
+	b, _ = Value[bool](c, key)
+	return
+}
+
+// GetInt 返回 key 对应的 int 值
+func (c *Context) GetInt(key string) (i int) {
+	i, _ = Value[int](c, key)
+	return
+}
+
+// GetInt64 返回 key 对应的 int64 值
+func (c *Context) GetInt64(key string) (i64 int64) {
+	i64, _ = Value[int64](c, key)
+	return
+}
+
+// GetUint 返回 key 对应的 uint 值
+func (c *Context) GetUint(key string) (ui uint) {
+	ui, _ = Value[uint](c, key)
+	return
+}
+
+// GetUint64 返回 key 对应的 uint64 值
+func (c *Context) GetUint64(key string) (ui64 uint64) {
+	ui64, _ = Value[uint64](c, key)
+	return
+}
+
+// GetFloat64 返回 key 对应的 float64 值
+func (c *Context) GetFloat64(key string) (f64 float64) {
+	f64, _ = Value[float64](c, key)
+	return
+}
+
+// GetTime 返回 key 对应的 time.Time 值
+func (c *Context) GetTime(key string) (t time.Time) {
+	t, _ = Value[time.Time](c, key)
+	return
+}
+
+// GetDuration 返回 key 对应的 time.Duration 值
+func (c *Context) GetDuration(key string) (d time.Duration) {
+	d, _ = Value[time.Duration](c, key)
+	return
+}
+
+// GetStringSlice 返回 key 对应的字符串切片
+func (c *Context) GetStringSlice(key string) (ss []string) {
+	ss, _ = Value[[]string](c, key)
+	return
+}
+
+// GetStringMap 返回 key 对应的 map[string]any
+func (c *Context) GetStringMap(key string) (sm map[string]any) {
+	sm, _ = Value[map[string]any](c, key)
+	return
+}
+
+// GetStringMapString 返回 key 对应的 map[string]string
+func (c *Context) GetStringMapString(key string) (sms map[string]string) {
+	sms, _ = Value[map[string]string](c, key)
+	return
+}
+
+// GetStringMapStringSlice 返回 key 对应的 map[string][]string
+func (c *Context) GetStringMapStringSlice(key string) (smss map[string][]string) {
+	smss, _ = Value[map[string][]string](c, key)
+	return
+}
+
+/************************************/
+/************ INPUT DATA ************/
+/************************************/
+
+// Param 返回 URL 参数的值，是 c.Params.ByName(key) 的简写
+//
+//	router.GET("/user/:id/*action", func(c *gon.Context) {
+//	    // GET 请求 /user/john/send
+//	    id := c.Param("id")         // id == "john"
+//	    action := c.Param("action") // action == "/send"
+//	})
+func (c *Context) Param(key string) string {
+	c.checkReleased()
+	return c.Params.ByName(key)
+}
+
+// AddParam 向 Context 中添加一个 URL 参数，主要用于在测试中模拟路由参数
+func (c *Context) AddParam(key, value string) {
+	c.Params = append(c.Params, Param{Key: key, Value: value})
+}
+
+// initQueryCache 解析请求的查询字符串并缓存，同一个请求只会解析一次
+func (c *Context) initQueryCache() {
+	if c.queryCache == nil {
+		if c.Request != nil && c.Request.URL != nil {
+			c.queryCache = c.Request.URL.Query()
+		} else {
+			c.queryCache = url.Values{}
+		}
+	}
+}
+
+// Query 返回查询字符串中 key 对应的第一个值，不存在时返回空字符串，是 c.Request.URL.Query().Get(key) 的简写
+//
+//	GET /path?id=1234&name=Manu&value=
+//	c.Query("id") == "1234"
+//	c.Query("name") == "Manu"
+//	c.Query("value") == ""
+//	c.Query("wtf") == ""
+func (c *Context) Query(key string) (value string) {
+	value, _ = c.GetQuery(key)
+	return
+}
+
+// DefaultQuery 返回查询字符串中 key 对应的第一个值，不存在时返回 defaultValue
+//
+//	GET /?name=Manu&lastname=
+//	c.DefaultQuery("name", "unknown") == "Manu"
+//	c.DefaultQuery("id", "none") == "none"
+//	c.DefaultQuery("lastname", "none") == ""
+func (c *Context) DefaultQuery(key, defaultValue string) string {
+	if value, ok := c.GetQuery(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// GetQuery 和 Query 类似，同时返回 key 是否存在，用于区分参数不存在和参数值为空字符串的情况
+//
+//	GET /?name=Manu&lastname=
+//	("Manu", true) == c.GetQuery("name")
+//	("", false) == c.GetQuery("id")
+//	("", true) == c.GetQuery("lastname")
+func (c *Context) GetQuery(key string) (string, bool) {
+	if values, ok := c.GetQueryArray(key); ok {
+		return values[0], ok
+	}
+	return "", false
+}
+
+// QueryArray 返回查询字符串中 key 对应的所有值，不存在时返回空切片
+func (c *Context) QueryArray(key string) (values []string) {
+	values, _ = c.GetQueryArray(key)
+	return
+}
+
+// GetQueryArray 返回查询字符串中 key 对应的所有值，以及 key 是否存在
+func (c *Context) GetQueryArray(key string) (values []string, ok bool) {
+	c.checkReleased()
+	c.initQueryCache()
+	values, ok = c.queryCache[key]
+	return
+}
+
+// QueryMap 返回查询字符串中以 key 为名称的 map 参数
+//
+//	GET /?ids[a]=1&ids[b]=2
+//	c.QueryMap("ids") == map[string]string{"a": "1", "b": "2"}
+func (c *Context) QueryMap(key string) (dicts map[string]string) {
+	dicts, _ = c.GetQueryMap(key)
+	return
+}
+
+// GetQueryMap 返回查询字符串中以 key 为名称的 map 参数，以及是否至少存在一个这样的参数
+func (c *Context) GetQueryMap(key string) (map[string]string, bool) {
+	c.checkReleased()
+	c.initQueryCache()
+	return c.get(c.queryCache, key)
+}
+
+// initFormCache 解析请求体中的表单参数并缓存，同一个请求只会解析一次
+// multipart/form-data 请求体使用 engine.MaxMultipartMemory 限制解析时使用的内存
+func (c *Context) initFormCache() {
+	if c.formCache == nil {
+		c.formCache = make(url.Values)
+		if c.Request == nil {
+			return
+		}
+		req := c.Request
+		if err := req.ParseMultipartForm(c.engine.MaxMultipartMemory); err != nil {
+			if !errors.Is(err, http.ErrNotMultipart) {
+				debugPrint("error on parse multipart form array: %v", err)
+			}
+		}
+		c.formCache = req.PostForm
+	}
+}
 
-	mu       sync.RWMutex        // 读写锁，用于保护 Context 的并发访问
+// PostForm 返回 urlencoded 表单或 multipart 表单中 key 对应的第一个值，不存在时返回空字符串
+func (c *Context) PostForm(key string) (value string) {
+	value, _ = c.GetPostForm(key)
+	return
+}
+
+// DefaultPostForm 返回 urlencoded 表单或 multipart 表单中 key 对应的第一个值，不存在时返回 defaultValue
+func (c *Context) DefaultPostForm(key, defaultValue string) string {
+	if value, ok := c.GetPostForm(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// GetPostForm 和 PostForm 类似，同时返回 key 是否存在，用于区分参数不存在和参数值为空字符串的情况
+//
+//	email=mail@example.com  -->  ("mail@example.com", true) := GetPostForm("email") // 设置 email 为 "mail@example.com"
+//	email=                  -->  ("", true) := GetPostForm("email")                 // 设置 email 为 ""
+//	                        -->  ("", false) := GetPostForm("email")                // 没有设置 email
+func (c *Context) GetPostForm(key string) (string, bool) {
+	if values, ok := c.GetPostFormArray(key); ok {
+		return values[0], ok
+	}
+	return "", false
+}
+
+// PostFormArray 返回 urlencoded 表单或 multipart 表单中 key 对应的所有值，不存在时返回空切片
+func (c *Context) PostFormArray(key string) (values []string) {
+	values, _ = c.GetPostFormArray(key)
+	return
+}
+
+// GetPostFormArray 返回 urlencoded 表单或 multipart 表单中 key 对应的所有值，以及 key 是否存在
+func (c *Context) GetPostFormArray(key string) (values []string, ok bool) {
+	c.checkReleased()
+	c.initFormCache()
+	values, ok = c.formCache[key]
+	return
+}
+
+// PostFormMap 返回 urlencoded 表单或 multipart 表单中以 key 为名称的 map 参数
+//
+//	POST /  ids[a]=1&ids[b]=2
+//	c.PostFormMap("ids") == map[string]string{"a": "1", "b": "2"}
+func (c *Context) PostFormMap(key string) (dicts map[string]string) {
+	dicts, _ = c.GetPostFormMap(key)
+	return
+}
+
+// GetPostFormMap 返回 urlencoded 表单或 multipart 表单中以 key 为名称的 map 参数，以及是否至少存在一个这样的参数
+func (c *Context) GetPostFormMap(key string) (map[string]string, bool) {
+	c.checkReleased()
+	c.initFormCache()
+	return c.get(c.formCache, key)
+}
+
+// get 从 m 中取出所有形如 key[k]=v 的参数，组成 map[k]v 返回，同一个 k 存在多个值时只使用第一个值
+func (c *Context) get(m map[string][]string, key string) (map[string]string, bool) {
+	dicts := make(map[string]string)
+	exist := false
+	for k, v := range m {
+		if i := strings.IndexByte(k, '['); i >= 1 && k[0:i] == key {
+			if j := strings.IndexByte(k[i+1:], ']'); j >= 1 {
+				exist = true
+				dicts[k[i+1:][:j]] = v[0]
+			}
+		}
+	}
+	return dicts, exist
+}
+
+// Bind 根据请求方式和 Content-Type 选择绑定器，将请求中的数据写入 obj
+// 绑定器的选择规则见 binding.Default，绑定失败时会中止请求并返回 400，如果需要自行处理错误，请使用 ShouldBind
+//
+//	"application/json" --> JSON 绑定器
+//	"application/xml"  --> XML 绑定器
+func (c *Context) Bind(obj any) error {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	return c.MustBindWith(obj, b)
+}
+
+// BindJSON 是 c.MustBindWith(obj, binding.JSON) 的简写
+func (c *Context) BindJSON(obj any) error {
+	return c.MustBindWith(obj, binding.JSON)
+}
+
+// BindXML 是 c.MustBindWith(obj, binding.XML) 的简写
+func (c *Context) BindXML(obj any) error {
+	return c.MustBindWith(obj, binding.XML)
+}
+
+// BindQuery 是 c.MustBindWith(obj, binding.Query) 的简写
+func (c *Context) BindQuery(obj any) error {
+	return c.MustBindWith(obj, binding.Query)
+}
+
+// BindHeader 是 c.MustBindWith(obj, binding.Header) 的简写
+func (c *Context) BindHeader(obj any) error {
+	return c.MustBindWith(obj, binding.Header)
+}
+
+// BindUri 使用 binding.Uri 将路由参数写入 obj，绑定失败时会中止请求并返回 400
+func (c *Context) BindUri(obj any) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind)
+		return err
+	}
+	return nil
+}
+
+// MustBindWith 使用指定的绑定器将请求中的数据写入 obj，绑定失败时会中止请求并返回 400，错误类型为 ErrorTypeBind
+func (c *Context) MustBindWith(obj any, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind)
+		return err
+	}
+	return nil
+}
+
+// ShouldBind 和 Bind 一样根据请求方式和 Content-Type 选择绑定器，但绑定失败时只返回错误，不会中止请求
+func (c *Context) ShouldBind(obj any) error {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	return c.ShouldBindWith(obj, b)
+}
+
+// ShouldBindJSON 是 c.ShouldBindWith(obj, binding.JSON) 的简写
+func (c *Context) ShouldBindJSON(obj any) error {
+	return c.ShouldBindWith(obj, binding.JSON)
+}
+
+// ShouldBindXML 是 c.ShouldBindWith(obj, binding.XML) 的简写
+func (c *Context) ShouldBindXML(obj any) error {
+	return c.ShouldBindWith(obj, binding.XML)
+}
+
+// ShouldBindQuery 是 c.ShouldBindWith(obj, binding.Query) 的简写
+func (c *Context) ShouldBindQuery(obj any) error {
+	return c.ShouldBindWith(obj, binding.Query)
+}
+
+// ShouldBindHeader 是 c.ShouldBindWith(obj, binding.Header) 的简写
+func (c *Context) ShouldBindHeader(obj any) error {
+	return c.ShouldBindWith(obj, binding.Header)
+}
+
+// ShouldBindUri 使用 binding.Uri 将路由参数写入 obj
+//
+//	router.GET("/users/:id", func(c *gon.Context) {
+//	    var user struct {
+//	        ID int `uri:"id"`
+//	    }
+//	    err := c.ShouldBindUri(&user)
+//	})
+func (c *Context) ShouldBindUri(obj any) error {
+	m := make(map[string][]string, len(c.Params))
+	for _, v := range c.Params {
+		m[v.Key] = []string{v.Value}
+	}
+	return binding.Uri.BindUri(m, obj)
+}
+
+// ShouldBindWith 使用指定的绑定器将请求中的数据写入 obj，绑定失败时只返回错误，不会中止请求
+func (c *Context) ShouldBindWith(obj any, b binding.Binding) error {
+	return b.Bind(c.Request, obj)
+}
+
+// ShouldBindBodyWith 和 ShouldBindWith 类似，但会将请求体保存到 Keys 中，同一个请求可以使用不同的绑定器多次绑定
+// 请求体只能读取一次，需要多次绑定时应该使用该方法，只有 binding.BindingBody 类型的绑定器可以使用
+func (c *Context) ShouldBindBodyWith(obj any, bb binding.BindingBody) (err error) {
+	var body []byte
+	if cb, ok := c.Get(BodyBytesKey); ok {
+		if cbb, ok := cb.([]byte); ok {
+			body = cbb
+		}
+	}
+	if body == nil {
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		c.Set(BodyBytesKey, body)
+	}
+	return bb.BindBody(body, obj)
+}
+
+// ContentType 返回请求的 Content-Type，不包含 charset 等参数
+func (c *Context) ContentType() string {
+	return filterFlags(c.requestHeader("Content-Type"))
+}
+
+// requestHeader 返回请求头中 key 对应的值
+func (c *Context) requestHeader(key string) string {
+	return c.Request.Header.Get(key)
+}
+
+/************************************/
+/*********** FLOW CONTROL ***********/
+/************************************/
+
+// Next 只应该在中间件中调用，它会执行处理链中当前处理函数之后的处理函数
+func (c *Context) Next() {
+	c.checkReleased()
+	c.index++
+	for c.index < int8(len(c.handlers)) {
+		c.handlers[c.index](c)
+		c.index++
+	}
+}
+
+// IsAborted 返回当前 Context 是否已经被中止
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+// Abort 中止处理链，阻止后续的处理函数被调用，但不会中止当前正在执行的处理函数
+// 例如鉴权中间件校验失败时，可以调用 Abort 阻止后续的处理函数执行
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+// AbortWithStatus 调用 Abort 并写入给定的状态码
+// 例如鉴权失败时可以调用 context.AbortWithStatus(401)
+func (c *Context) AbortWithStatus(code int) {
+	c.Status(code)
+	c.Writer.WriteHeaderNow()
+	c.Abort()
+}
+
+// AbortWithStatusJSON 调用 Abort 并将给定的状态码和对象以 JSON 格式写入响应
+func (c *Context) AbortWithStatusJSON(code int, jsonObj any) {
+	c.Abort()
+	c.JSON(code, jsonObj)
+}
+
+// AbortWithError 调用 AbortWithStatus 并将错误记录到 Context.Errors 中
+func (c *Context) AbortWithError(code int, err error) *Error {
+	c.AbortWithStatus(code)
+	return c.Error(err)
+}
+
+/************************************/
+/********* ERROR MANAGEMENT *********/
+/************************************/
+
+// Error 将错误记录到当前 Context 中，中间件可以在处理链结束后统一处理这些错误
+// 传入的 err 为 nil 时会触发 panic
+func (c *Context) Error(err error) *Error {
+	if err == nil {
+		panic("err is nil")
+	}
+
+	var parsedError *Error
+	ok := errors.As(err, &parsedError)
+	if !ok {
+		parsedError = &Error{
+			Err:  err,
+			Type: ErrorTypePrivate,
+		}
+	}
+
+	c.Errors = append(c.Errors, parsedError)
+	return parsedError
+}
+
+/************************************/
+/******** RESPONSE RENDERING ********/
+/************************************/
+
+// bodyAllowedForStatus 判断给定的状态码是否允许携带响应体，参考 http 包中的同名函数
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// Status 设置 HTTP 响应状态码
+func (c *Context) Status(code int) {
+	c.checkReleased()
+	c.Writer.WriteHeader(code)
+}
+
+// Render 写入响应状态码并使用给定的渲染器渲染响应数据
+func (c *Context) Render(code int, r render.Render) {
+	c.Status(code)
+
+	if !bodyAllowedForStatus(code) {
+		r.WriteContentType(c.Writer)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	if err := r.Render(c.Writer); err != nil {
+		// 将渲染错误记录到 c.Errors 中
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// JSON 将给定的对象序列化为 JSON 写入响应，并设置 Content-Type 为 "application/json"
+func (c *Context) JSON(code int, obj any) {
+	c.Render(code, render.JSON{Data: obj})
+}
+
+/************************************/
+/***** GOLANG.ORG/X/NET/CONTEXT *****/
+/************************************/
+
+// 确保 Context 实现了 context.Context 接口
+var _ context.Context = (*Context)(nil)
+
+// hasRequestContext 判断是否开启了 ContextWithFallback 且 c.Request 存在可以回退的 context.Context
+func (c *Context) hasRequestContext() bool {
+	c.checkReleased()
+	hasFallback := c.engine != nil && c.engine.ContextWithFallback
+	hasRequestContext := c.Request != nil && c.Request.Context() != nil
+	return hasFallback && hasRequestContext
+}
+
+// Deadline 返回 c.Request.Context() 的截止时间，没有开启 ContextWithFallback 时返回 ok 为 false
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	if !c.hasRequestContext() {
+		return
+	}
+	return c.Request.Context().Deadline()
+}
+
+// Done 返回 c.Request.Context() 的 Done 通道，请求被取消或超时时关闭，没有开启 ContextWithFallback 时返回 nil，即永远不会关闭
+func (c *Context) Done() <-chan struct{} {
+	if !c.hasRequestContext() {
+		return nil
+	}
+	return c.Request.Context().Done()
+}
+
+// Err 返回 c.Request.Context() 被取消的原因，没有开启 ContextWithFallback 时返回 nil
+func (c *Context) Err() error {
+	if !c.hasRequestContext() {
+		return nil
+	}
+	return c.Request.Context().Err()
+}
 
+// Value 返回 key 对应的值，依次查找以下位置，都不存在时返回 nil
+//   - key 为 ContextRequestKey 时返回 c.Request，为 ContextKey 时返回 c 自身
+//   - key 为字符串时查找 c.Keys
+//   - 开启 ContextWithFallback 时查找 c.Request.Context()
+func (c *Context) Value(key any) any {
+	if key == ContextRequestKey {
+		return c.Request
+	}
+	if key == ContextKey {
+		return c
+	}
+	if keyAsString, ok := key.(string); ok {
+		if val, exists := c.Get(keyAsString); exists {
+			return val
+		}
+	}
+	if !c.hasRequestContext() {
+		return nil
+	}
+	return c.Request.Context().Value(key)
 }
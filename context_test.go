@@ -0,0 +1,718 @@
+package gon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stolenzc/gon/binding"
+)
+
+// createTestContext 返回一个使用 req 作为请求的 Context，Context 从 Engine 中分配，和处理请求时的状态一致
+func createTestContext(req *http.Request) *Context {
+	engine := New()
+	c := engine.allocateContext(0, 0)
+	c.writermem.reset(httptest.NewRecorder())
+	c.Request = req
+	c.reset()
+	return c
+}
+
+func TestContextNext(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		chain func(trace *[]string) HandlerChain
+		want  []string
+	}{
+		{
+			name: "without next",
+			chain: func(trace *[]string) HandlerChain {
+				return HandlerChain{
+					func(c *Context) { *trace = append(*trace, "a") },
+					func(c *Context) { *trace = append(*trace, "b") },
+				}
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "next runs the rest of the chain before returning",
+			chain: func(trace *[]string) HandlerChain {
+				return HandlerChain{
+					func(c *Context) {
+						*trace = append(*trace, "a before")
+						c.Next()
+						*trace = append(*trace, "a after")
+					},
+					func(c *Context) {
+						*trace = append(*trace, "b before")
+						c.Next()
+						*trace = append(*trace, "b after")
+					},
+					func(c *Context) { *trace = append(*trace, "c") },
+				}
+			},
+			want: []string{"a before", "b before", "c", "b after", "a after"},
+		},
+		{
+			name: "next in the last handler",
+			chain: func(trace *[]string) HandlerChain {
+				return HandlerChain{
+					func(c *Context) { *trace = append(*trace, "a") },
+					func(c *Context) {
+						c.Next()
+						*trace = append(*trace, "b")
+					},
+				}
+			},
+			want: []string{"a", "b"},
+		},
+	} {
+		var trace []string
+		router := New()
+		router.GET("/", tt.chain(&trace)...)
+		performRequest(router, http.MethodGet, "/")
+		if !slices.Equal(trace, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, trace, tt.want)
+		}
+	}
+}
+
+func TestContextAbort(t *testing.T) {
+	errBoom := errors.New("boom")
+	for _, tt := range []struct {
+		name        string
+		abort       HandlerFunc
+		code        int
+		body        string
+		contentType string
+		errs        []error
+	}{
+		{"Abort", func(c *Context) { c.Abort() }, http.StatusOK, "", "", nil},
+		{"AbortWithStatus", func(c *Context) { c.AbortWithStatus(http.StatusUnauthorized) }, http.StatusUnauthorized, "", "", nil},
+		{
+			"AbortWithStatusJSON",
+			func(c *Context) { c.AbortWithStatusJSON(http.StatusBadRequest, map[string]string{"error": "bad"}) },
+			http.StatusBadRequest, "{\"error\":\"bad\"}", "application/json; charset=utf-8", nil,
+		},
+		{
+			"AbortWithError",
+			func(c *Context) {
+				if e := c.AbortWithError(http.StatusInternalServerError, errBoom); e.Err != errBoom || e.Type != ErrorTypePrivate {
+					t.Errorf("AbortWithError returned %+v", e)
+				}
+			},
+			http.StatusInternalServerError, "", "", []error{errBoom},
+		},
+	} {
+		var after bool
+		var aborted bool
+		var errs []error
+		router := New()
+		router.GET("/",
+			func(c *Context) {
+				c.Next()
+				// Abort 不会中止当前正在执行的处理函数，已经在执行的中间件会继续执行
+				aborted = c.IsAborted()
+				for _, e := range c.Errors {
+					errs = append(errs, e.Err)
+				}
+			},
+			tt.abort,
+			func(c *Context) { after = true },
+		)
+
+		w := performRequest(router, http.MethodGet, "/")
+		if after || !aborted {
+			t.Errorf("%s: later handler called %v, aborted %v", tt.name, after, aborted)
+		}
+		if w.Code != tt.code || w.Body.String() != tt.body || w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: got %d %q %q, want %d %q %q", tt.name,
+				w.Code, w.Body.String(), w.Header().Get("Content-Type"), tt.code, tt.body, tt.contentType)
+		}
+		if !slices.Equal(errs, tt.errs) {
+			t.Errorf("%s: got errors %v, want %v", tt.name, errs, tt.errs)
+		}
+	}
+}
+
+func contextTestMiddleware(c *Context) { c.Next() }
+
+func contextTestHandler(c *Context) {}
+
+func TestContextHandlerNames(t *testing.T) {
+	var name string
+	var names []string
+	router := New()
+	router.GET("/",
+		contextTestMiddleware,
+		func(c *Context) {
+			name, names = c.HandlerName(), c.HandlerNames()
+			if c.Handler() == nil {
+				t.Error("Handler returned nil")
+			}
+		},
+		contextTestHandler,
+	)
+	performRequest(router, http.MethodGet, "/")
+
+	if want := "github.com/stolenzc/gon.contextTestHandler"; name != want {
+		t.Errorf("HandlerName = %q, want %q", name, want)
+	}
+	want := []string{
+		"github.com/stolenzc/gon.contextTestMiddleware",
+		"github.com/stolenzc/gon.TestContextHandlerNames.func1",
+		"github.com/stolenzc/gon.contextTestHandler",
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("HandlerNames = %q, want %q", names, want)
+	}
+}
+
+func TestContextParam(t *testing.T) {
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.AddParam("id", "42")
+	c.AddParam("empty", "")
+	c.AddParam("id", "43")
+
+	if got := c.Param("id"); got != "42" {
+		t.Errorf("Param(id) = %q, want 42", got)
+	}
+	for _, tt := range []struct {
+		key   string
+		value string
+		ok    bool
+	}{
+		{"id", "42", true},
+		{"empty", "", true},
+		{"missing", "", false},
+	} {
+		if value, ok := c.Params.Get(tt.key); value != tt.value || ok != tt.ok {
+			t.Errorf("Params.Get(%q) = %q, %v, want %q, %v", tt.key, value, ok, tt.value, tt.ok)
+		}
+		if got := c.Params.ByName(tt.key); got != tt.value {
+			t.Errorf("Params.ByName(%q) = %q, want %q", tt.key, got, tt.value)
+		}
+	}
+}
+
+func TestContextParamRawPath(t *testing.T) {
+	for _, tt := range []struct {
+		useRawPath bool
+		unescape   bool
+		path       string
+		code       int
+		value      string
+	}{
+		{false, true, "/files/hello%20world", http.StatusOK, "hello world"},
+		{false, true, "/files/a%2Fb", http.StatusNotFound, ""}, // url.Path 中 "%2F" 已经被解码为 "/"
+		{true, true, "/files/a%2Fb", http.StatusOK, "a/b"},
+		{true, false, "/files/a%2Fb", http.StatusOK, "a%2Fb"},
+		{true, false, "/files/hello%20world", http.StatusOK, "hello world"}, // 没有 RawPath 时使用 url.Path
+	} {
+		router := New()
+		router.UseRawPath = tt.useRawPath
+		router.UnescapePathValues = tt.unescape
+		var value string
+		router.GET("/files/:name", func(c *Context) { value = c.Param("name") })
+
+		w := performRequest(router, http.MethodGet, tt.path)
+		if w.Code != tt.code || value != tt.value {
+			t.Errorf("UseRawPath=%v UnescapePathValues=%v %s: got %d %q, want %d %q",
+				tt.useRawPath, tt.unescape, tt.path, w.Code, value, tt.code, tt.value)
+		}
+	}
+}
+
+func TestContextQuery(t *testing.T) {
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/?foo=bar&page=10&id=&both=GET&ids[a]=hi&ids[b]=3.14&list=a&list=b", nil))
+
+	if got := c.Query("foo"); got != "bar" {
+		t.Errorf("Query(foo) = %q, want bar", got)
+	}
+	if got := c.DefaultQuery("page", "0"); got != "10" {
+		t.Errorf("DefaultQuery(page) = %q, want 10", got)
+	}
+	if got := c.DefaultQuery("nokey", "default"); got != "default" {
+		t.Errorf("DefaultQuery(nokey) = %q, want default", got)
+	}
+	if value, ok := c.GetQuery("id"); !ok || value != "" {
+		t.Errorf("GetQuery(id) = %q, %v, want \"\", true", value, ok)
+	}
+	if value, ok := c.GetQuery("nokey"); ok || value != "" {
+		t.Errorf("GetQuery(nokey) = %q, %v, want \"\", false", value, ok)
+	}
+	if got := c.QueryArray("list"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("QueryArray(list) = %v, want [a b]", got)
+	}
+	if got := c.QueryArray("nokey"); len(got) != 0 {
+		t.Errorf("QueryArray(nokey) = %v, want empty", got)
+	}
+	if got := c.QueryMap("ids"); !reflect.DeepEqual(got, map[string]string{"a": "hi", "b": "3.14"}) {
+		t.Errorf("QueryMap(ids) = %v", got)
+	}
+	if dicts, ok := c.GetQueryMap("nokey"); ok || len(dicts) != 0 {
+		t.Errorf("GetQueryMap(nokey) = %v, %v, want empty, false", dicts, ok)
+	}
+	if got := c.PostForm("foo"); got != "" {
+		t.Errorf("PostForm(foo) = %q, want empty for GET request", got)
+	}
+
+	// 查询字符串只会解析一次，之后修改请求不会影响已经缓存的结果
+	c.Request.URL.RawQuery = "foo=changed"
+	if got := c.Query("foo"); got != "bar" {
+		t.Errorf("Query(foo) after change = %q, want cached bar", got)
+	}
+}
+
+func TestContextPostForm(t *testing.T) {
+	body := bytes.NewBufferString("foo=bar&page=11&both=&ids[a]=hi&ids[b]=3.14&list=a&list=b")
+	req := httptest.NewRequest(http.MethodPost, "/?both=GET&id=main", body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c := createTestContext(req)
+
+	if got := c.PostForm("foo"); got != "bar" {
+		t.Errorf("PostForm(foo) = %q, want bar", got)
+	}
+	if got := c.DefaultPostForm("page", "0"); got != "11" {
+		t.Errorf("DefaultPostForm(page) = %q, want 11", got)
+	}
+	if got := c.DefaultPostForm("nokey", "default"); got != "default" {
+		t.Errorf("DefaultPostForm(nokey) = %q, want default", got)
+	}
+	if value, ok := c.GetPostForm("both"); !ok || value != "" {
+		t.Errorf("GetPostForm(both) = %q, %v, want \"\", true", value, ok)
+	}
+	if _, ok := c.GetPostForm("id"); ok {
+		t.Error("GetPostForm(id) found a query string parameter")
+	}
+	if got := c.PostFormArray("list"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("PostFormArray(list) = %v, want [a b]", got)
+	}
+	if got := c.PostFormMap("ids"); !reflect.DeepEqual(got, map[string]string{"a": "hi", "b": "3.14"}) {
+		t.Errorf("PostFormMap(ids) = %v", got)
+	}
+	if got := c.Query("both"); got != "GET" {
+		t.Errorf("Query(both) = %q, want GET", got)
+	}
+}
+
+func TestContextMultipartForm(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	_ = mw.WriteField("foo", "bar")
+	_ = mw.WriteField("ids[a]", "1")
+	w, _ := mw.CreateFormFile("file", "test.txt")
+	_, _ = w.Write([]byte(strings.Repeat("x", 1024)))
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	c := createTestContext(req)
+	c.engine.MaxMultipartMemory = 8
+
+	if got := c.PostForm("foo"); got != "bar" {
+		t.Errorf("PostForm(foo) = %q, want bar", got)
+	}
+	if got := c.PostFormMap("ids"); !reflect.DeepEqual(got, map[string]string{"a": "1"}) {
+		t.Errorf("PostFormMap(ids) = %v", got)
+	}
+}
+
+func TestContextCachesResetOnReuse(t *testing.T) {
+	router := New()
+	var got []string
+	router.POST("/", func(c *Context) {
+		got = append(got, c.Query("q")+","+c.PostForm("f"))
+	})
+
+	for _, v := range []string{"1", "2"} {
+		req := httptest.NewRequest(http.MethodPost, "/?q="+v, strings.NewReader("f="+v))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if want := []string{"1,1", "2,2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestContextSetGet(t *testing.T) {
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Set("foo", "bar")
+
+	value, ok := c.Get("foo")
+	if !ok || value != "bar" {
+		t.Errorf("Get(foo) = %v, %v, want bar, true", value, ok)
+	}
+	if value, ok := c.Get("foo2"); ok || value != nil {
+		t.Errorf("Get(foo2) = %v, %v, want nil, false", value, ok)
+	}
+	if got := c.MustGet("foo"); got != "bar" {
+		t.Errorf("MustGet(foo) = %v, want bar", got)
+	}
+	if catchPanic(func() { c.MustGet("no_exist") }) == nil {
+		t.Error("MustGet(no_exist) did not panic")
+	}
+}
+
+func TestContextTypedGetters(t *testing.T) {
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	now := time.Now()
+	c.Set("string", "this is a string")
+	c.Set("bool", true)
+	c.Set("int", 1)
+	c.Set("int64", int64(42424242424242))
+	c.Set("uint", uint(1))
+	c.Set("uint64", uint64(18446744073709551615))
+	c.Set("float64", 4.2)
+	c.Set("time", now)
+	c.Set("duration", time.Second)
+	c.Set("slice", []string{"foo"})
+	c.Set("map", map[string]any{"foo": 1})
+	c.Set("mapString", map[string]string{"foo": "bar"})
+	c.Set("mapStringSlice", map[string][]string{"foo": {"bar"}})
+
+	if got := c.GetString("string"); got != "this is a string" {
+		t.Errorf("GetString = %q", got)
+	}
+	if !c.GetBool("bool") {
+		t.Error("GetBool = false")
+	}
+	if got := c.GetInt("int"); got != 1 {
+		t.Errorf("GetInt = %d", got)
+	}
+	if got := c.GetInt64("int64"); got != 42424242424242 {
+		t.Errorf("GetInt64 = %d", got)
+	}
+	if got := c.GetUint("uint"); got != 1 {
+		t.Errorf("GetUint = %d", got)
+	}
+	if got := c.GetUint64("uint64"); got != 18446744073709551615 {
+		t.Errorf("GetUint64 = %d", got)
+	}
+	if got := c.GetFloat64("float64"); got != 4.2 {
+		t.Errorf("GetFloat64 = %v", got)
+	}
+	if got := c.GetTime("time"); !got.Equal(now) {
+		t.Errorf("GetTime = %v", got)
+	}
+	if got := c.GetDuration("duration"); got != time.Second {
+		t.Errorf("GetDuration = %v", got)
+	}
+	if got := c.GetStringSlice("slice"); !reflect.DeepEqual(got, []string{"foo"}) {
+		t.Errorf("GetStringSlice = %v", got)
+	}
+	if got := c.GetStringMap("map"); !reflect.DeepEqual(got, map[string]any{"foo": 1}) {
+		t.Errorf("GetStringMap = %v", got)
+	}
+	if got := c.GetStringMapString("mapString"); !reflect.DeepEqual(got, map[string]string{"foo": "bar"}) {
+		t.Errorf("GetStringMapString = %v", got)
+	}
+	if got := c.GetStringMapStringSlice("mapStringSlice"); !reflect.DeepEqual(got, map[string][]string{"foo": {"bar"}}) {
+		t.Errorf("GetStringMapStringSlice = %v", got)
+	}
+
+	// 类型不匹配或 key 不存在时返回零值
+	if got := c.GetInt("string"); got != 0 {
+		t.Errorf("GetInt(string) = %d, want 0", got)
+	}
+	if got := c.GetString("no_exist"); got != "" {
+		t.Errorf("GetString(no_exist) = %q, want empty", got)
+	}
+}
+
+func TestContextValue(t *testing.T) {
+	type user struct{ name string }
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Set("user", &user{name: "gon"})
+
+	if u, ok := Value[*user](c, "user"); !ok || u.name != "gon" {
+		t.Errorf("Value[*user] = %v, %v", u, ok)
+	}
+	if s, ok := Value[string](c, "user"); ok || s != "" {
+		t.Errorf("Value[string] = %q, %v, want \"\", false", s, ok)
+	}
+	if u, ok := Value[*user](c, "no_exist"); ok || u != nil {
+		t.Errorf("Value[*user](no_exist) = %v, %v, want nil, false", u, ok)
+	}
+}
+
+func TestContextKeysConcurrent(t *testing.T) {
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := strconv.Itoa(i)
+			c.Set(key, i)
+			if got := c.GetInt(key); got != i {
+				t.Errorf("GetInt(%s) = %d, want %d", key, got, i)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(c.Keys) != 10 {
+		t.Errorf("got %d keys, want 10", len(c.Keys))
+	}
+}
+
+func TestContextKeysResetOnReuse(t *testing.T) {
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Set("foo", "bar")
+	c.reset()
+	if _, ok := c.Get("foo"); ok {
+		t.Error("Keys not cleared by reset")
+	}
+}
+
+type contextKey string
+
+func TestContextWithFallback(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), contextKey("foo"), "bar"), time.Minute)
+	defer cancel()
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	c.engine.ContextWithFallback = true
+
+	if deadline, ok := c.Deadline(); !ok || deadline.IsZero() {
+		t.Errorf("Deadline() = %v, %v, want request deadline", deadline, ok)
+	}
+	if got := c.Value(contextKey("foo")); got != "bar" {
+		t.Errorf("Value(foo) = %v, want bar", got)
+	}
+	if c.Done() == nil || c.Err() != nil {
+		t.Fatal("Done() or Err() does not fall back to the request context")
+	}
+
+	cancel()
+	<-c.Done()
+	if !errors.Is(c.Err(), context.Canceled) {
+		t.Errorf("Err() = %v, want context.Canceled", c.Err())
+	}
+}
+
+func TestContextWithoutFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey("foo"), "bar"))
+	cancel()
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	if _, ok := c.Deadline(); ok {
+		t.Error("Deadline() ok without ContextWithFallback")
+	}
+	if c.Done() != nil || c.Err() != nil {
+		t.Error("Done() or Err() falls back to the request context without ContextWithFallback")
+	}
+	if got := c.Value(contextKey("foo")); got != nil {
+		t.Errorf("Value(foo) = %v, want nil", got)
+	}
+}
+
+func TestContextValueLookup(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey("foo"), "request")
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	c.engine.ContextWithFallback = true
+	c.Set("foo", "keys")
+
+	if got := c.Value(ContextRequestKey); got != c.Request {
+		t.Errorf("Value(ContextRequestKey) = %v, want c.Request", got)
+	}
+	if got := c.Value(ContextKey); got != c {
+		t.Errorf("Value(ContextKey) = %v, want c", got)
+	}
+	if got := c.Value("foo"); got != "keys" {
+		t.Errorf("Value(\"foo\") = %v, want keys", got)
+	}
+	if got := c.Value(contextKey("foo")); got != "request" {
+		t.Errorf("Value(contextKey(foo)) = %v, want request", got)
+	}
+	if got := c.Value("no_exist"); got != nil {
+		t.Errorf("Value(no_exist) = %v, want nil", got)
+	}
+}
+
+func TestContextCopy(t *testing.T) {
+	c := createTestContext(httptest.NewRequest(http.MethodGet, "/hola", nil))
+	c.index = 2
+	c.handlers = HandlerChain{func(c *Context) {}}
+	c.fullPath = "/:name"
+	c.Params = Params{Param{Key: "name", Value: "hola"}}
+	c.Set("foo", "bar")
+
+	cp := c.Copy()
+	if cp.handlers != nil || cp.index != abortIndex || !cp.IsAborted() {
+		t.Error("copy is not aborted or still has handlers")
+	}
+	if cp.Request != c.Request || cp.FullPath() != "/:name" || cp.Param("name") != "hola" || cp.MustGet("foo") != "bar" {
+		t.Error("copy does not contain the request data")
+	}
+	if cp.Writer != &cp.writermem || cp.writermem.ResponseWriter != nil {
+		t.Error("copy shares the response writer")
+	}
+
+	// 修改原 Context 不会影响副本
+	c.Params[0].Value = "adios"
+	c.Set("foo", "baz")
+	if cp.Param("name") != "hola" || cp.MustGet("foo") != "bar" {
+		t.Error("copy shares Params or Keys with the original Context")
+	}
+}
+
+func TestContextUseAfterRelease(t *testing.T) {
+	router := New()
+	var stale, copied *Context
+	router.GET("/:name", func(c *Context) {
+		c.Set("foo", "bar")
+		stale, copied = c, c.Copy()
+	})
+
+	SetMode(DebugMode)
+	defer SetMode(TestMode)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/gon", nil))
+
+	for name, f := range map[string]func(){
+		"Param":          func() { stale.Param("name") },
+		"Get":            func() { stale.Get("foo") },
+		"Query":          func() { stale.Query("q") },
+		"GetQueryMap":    func() { stale.GetQueryMap("ids") },
+		"GetPostFormMap": func() { stale.GetPostFormMap("ids") },
+		"Copy":           func() { stale.Copy() },
+		"Value":          func() { stale.Value("foo") },
+		"HandleContext":  func() { router.HandleContext(stale) },
+	} {
+		if catchPanic(f) == nil {
+			t.Errorf("%s on released Context did not panic", name)
+		}
+	}
+	if copied.Param("name") != "gon" || copied.MustGet("foo") != "bar" {
+		t.Error("copy is not usable after the request has finished")
+	}
+}
+
+func TestContextNotReusedAfterRelease(t *testing.T) {
+	router := New()
+	var contexts []*Context
+	router.GET("/:name", func(c *Context) {
+		contexts = append(contexts, c)
+	})
+
+	// 调试模式下已释放的 Context 不会放回 engine.pool，之后的请求不会复用它，也不会清除它的释放标记
+	SetMode(DebugMode)
+	defer SetMode(TestMode)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/first", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/second", nil))
+
+	if len(contexts) != 2 || contexts[0] == contexts[1] {
+		t.Fatal("released Context was reused by the next request")
+	}
+	if catchPanic(func() { contexts[0].Param("name") }) == nil {
+		t.Error("released Context did not panic after the next request")
+	}
+}
+
+type bindTarget struct {
+	Foo string `json:"foo" xml:"foo" form:"foo" uri:"foo" header:"foo"`
+	Bar int    `json:"bar" xml:"bar" form:"bar" uri:"bar" header:"bar"`
+}
+
+func TestContextShouldBindByContentType(t *testing.T) {
+	for _, tt := range []struct {
+		method, contentType, body string
+	}{
+		{http.MethodPost, "application/json; charset=utf-8", `{"foo": "x", "bar": 1}`},
+		{http.MethodPut, "application/xml", `<root><foo>x</foo><bar>1</bar></root>`},
+		{http.MethodPost, "application/x-www-form-urlencoded", "foo=x&bar=1"},
+		{http.MethodGet, "application/json", ""},
+	} {
+		target := "/"
+		if tt.method == http.MethodGet {
+			target = "/?foo=x&bar=1"
+		}
+		req := httptest.NewRequest(tt.method, target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		c := createTestContext(req)
+
+		var obj bindTarget
+		if err := c.ShouldBind(&obj); err != nil || obj != (bindTarget{Foo: "x", Bar: 1}) {
+			t.Errorf("%s %s: got %+v, %v", tt.method, tt.contentType, obj, err)
+		}
+	}
+}
+
+func TestContextBindAbortsOnError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"bar": "NaN"}`))
+	req.Header.Set("Content-Type", "application/json")
+	c := createTestContext(req)
+
+	var obj bindTarget
+	if err := c.Bind(&obj); err == nil {
+		t.Fatal("Bind did not fail")
+	}
+	if !c.IsAborted() || c.Writer.Status() != http.StatusBadRequest {
+		t.Errorf("aborted = %v, status = %d, want aborted with 400", c.IsAborted(), c.Writer.Status())
+	}
+	if len(c.Errors) != 1 || c.Errors[0].Type != ErrorTypeBind {
+		t.Errorf("Errors = %v, want one ErrorTypeBind error", c.Errors)
+	}
+
+	c = createTestContext(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"bar": "NaN"}`)))
+	if err := c.ShouldBindJSON(&obj); err == nil || c.IsAborted() {
+		t.Errorf("ShouldBindJSON: err = %v, aborted = %v, want error without abort", err, c.IsAborted())
+	}
+}
+
+func TestContextBindUriAndHeader(t *testing.T) {
+	router := New()
+	var uri, header bindTarget
+	var uriErr, headerErr error
+	router.GET("/:foo/:bar", func(c *Context) {
+		uriErr = c.ShouldBindUri(&uri)
+		headerErr = c.BindHeader(&header)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/gon/42", nil)
+	req.Header.Set("Foo", "header")
+	req.Header.Set("Bar", "7")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if uriErr != nil || uri != (bindTarget{Foo: "gon", Bar: 42}) {
+		t.Errorf("ShouldBindUri = %+v, %v", uri, uriErr)
+	}
+	if headerErr != nil || header != (bindTarget{Foo: "header", Bar: 7}) {
+		t.Errorf("BindHeader = %+v, %v", header, headerErr)
+	}
+}
+
+func TestContextShouldBindBodyWith(t *testing.T) {
+	c := createTestContext(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"foo": "x", "bar": 1}`)))
+
+	// 请求体会被保存到 Keys 中，可以多次绑定
+	for i := 0; i < 2; i++ {
+		var obj bindTarget
+		if err := c.ShouldBindBodyWith(&obj, binding.JSON); err != nil || obj != (bindTarget{Foo: "x", Bar: 1}) {
+			t.Errorf("bind #%d: got %+v, %v", i, obj, err)
+		}
+	}
+	if _, ok := c.Get(BodyBytesKey); !ok {
+		t.Error("body was not stored in Keys")
+	}
+}
+
+func TestContextContentType(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	c := createTestContext(req)
+	if got := c.ContentType(); got != "application/json" {
+		t.Errorf("ContentType() = %q, want application/json", got)
+	}
+}
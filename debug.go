@@ -0,0 +1,68 @@
+package gon
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultEngines 记录 Default 被调用的次数，用于提示重复创建 Engine
+var defaultEngines atomic.Int32
+
+// IsDebugging 返回当前是否处于调试模式
+// 使用 SetMode(gon.ReleaseMode) 可以关闭调试模式
+func IsDebugging() bool {
+	return atomic.LoadInt32(&gonMode) == debugCode
+}
+
+// DebugPrintRouteFunc 用于自定义路由注册时调试信息的输出格式，为 nil 时使用默认格式
+var DebugPrintRouteFunc func(httpMethod, absolutePath, handlerName string, nuHandlers int)
+
+// DebugPrintFunc 用于自定义调试信息的输出方式，为 nil 时输出到 DefaultWriter
+var DebugPrintFunc func(format string, values ...any)
+
+// debugPrintRoute 在调试模式下输出注册的路由信息
+func debugPrintRoute(httpMethod, absolutePath string, handlers HandlerChain) {
+	if IsDebugging() {
+		nuHandlers := len(handlers)
+		handlerName := nameOfFunction(handlers.Last())
+		if DebugPrintRouteFunc == nil {
+			debugPrint("%-6s %-25s --> %s (%d handlers)\n", httpMethod, absolutePath, handlerName, nuHandlers)
+		} else {
+			DebugPrintRouteFunc(httpMethod, absolutePath, handlerName, nuHandlers)
+		}
+	}
+}
+
+// debugPrint 在调试模式下输出调试信息
+func debugPrint(format string, values ...any) {
+	if !IsDebugging() {
+		return
+	}
+
+	if DebugPrintFunc != nil {
+		DebugPrintFunc(format, values...)
+		return
+	}
+
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	fmt.Fprintf(DefaultWriter, "[GON-debug] "+format, values...)
+}
+
+// debugPrintWARNINGDefault 在调试模式下输出使用 Default 创建 Engine 时的警告信息
+func debugPrintWARNINGDefault() {
+	if n := defaultEngines.Add(1); n > 1 {
+		debugPrint("[WARNING] Default() has been called %d times. Make sure you are not creating multiple engines by mistake.", n)
+	}
+}
+
+// debugPrintWARNINGNew 在调试模式下输出使用 New 创建 Engine 时的警告信息
+func debugPrintWARNINGNew() {
+	debugPrint(`[WARNING] Running in "debug" mode. Switch to "release" mode in production.
+ - using env:	export GON_MODE=release
+ - using code:	gon.SetMode(gon.ReleaseMode)
+
+`)
+}
@@ -0,0 +1,80 @@
+package gon
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestDebugPrintRoute(t *testing.T) {
+	var buf bytes.Buffer
+	DefaultWriter = &buf
+	type call struct {
+		method, path, handler string
+		nuHandlers            int
+	}
+	var calls []call
+	defer func() {
+		SetMode(TestMode)
+		DefaultWriter = os.Stdout
+		DebugPrintRouteFunc = nil
+	}()
+
+	router := New()
+	router.Use(func(c *Context) {})
+	SetMode(DebugMode)
+	router.GET("/users/:id", showUser)
+	if want := fmt.Sprintf("[GON-debug] %-6s %-25s --> %s (%d handlers)\n", "GET", "/users/:id", "github.com/stolenzc/gon.showUser", 2); buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	DebugPrintRouteFunc = func(httpMethod, absolutePath, handlerName string, nuHandlers int) {
+		calls = append(calls, call{httpMethod, absolutePath, handlerName, nuHandlers})
+	}
+	router.POST("/users/:name?", listUsers)
+	want := []call{
+		{"POST", "/users", "github.com/stolenzc/gon.listUsers", 2},
+		{"POST", "/users/:name", "github.com/stolenzc/gon.listUsers", 2},
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("DebugPrintRouteFunc got %v, want %v", calls, want)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("DebugPrintRouteFunc set but got output %q", buf.String())
+	}
+
+	// 生产模式下不输出任何调试信息
+	SetMode(ReleaseMode)
+	DebugPrintRouteFunc = nil
+	New().GET("/", showUser)
+	router.PUT("/users/:id", showUser)
+	if buf.Len() != 0 {
+		t.Errorf("release mode got output %q", buf.String())
+	}
+}
+
+func TestDebugPrintFunc(t *testing.T) {
+	var got string
+	DebugPrintFunc = func(format string, values ...any) {
+		got = fmt.Sprintf(format, values...)
+	}
+	defer func() {
+		SetMode(TestMode)
+		DebugPrintFunc = nil
+	}()
+
+	SetMode(DebugMode)
+	debugPrint("hello %s", "gon")
+	if got != "hello gon" {
+		t.Errorf("got %q, want %q", got, "hello gon")
+	}
+
+	got = ""
+	SetMode(TestMode)
+	debugPrint("hello %s", "gon")
+	if got != "" {
+		t.Errorf("test mode got %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+package gon
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrorType 是 gon 中定义的错误类型，使用位掩码表示，可以组合多个类型
+type ErrorType uint64
+
+const (
+	// ErrorTypeBind 在 Context.Bind() 失败时使用
+	ErrorTypeBind ErrorType = 1 << 63
+	// ErrorTypeRender 在 Context.Render() 失败时使用
+	ErrorTypeRender ErrorType = 1 << 62
+	// ErrorTypePrivate 表示私有错误
+	ErrorTypePrivate ErrorType = 1 << 0
+	// ErrorTypePublic 表示公开错误
+	ErrorTypePublic ErrorType = 1 << 1
+	// ErrorTypeAny 表示任意错误类型
+	ErrorTypeAny ErrorType = 1<<64 - 1
+)
+
+// Error 表示一个错误的详细信息
+type Error struct {
+	Err  error     // 原始错误
+	Type ErrorType // 错误类型
+	Meta any       // 错误的元数据
+}
+
+// errorMsgs 是请求处理过程中记录的错误列表
+type errorMsgs []*Error
+
+var _ error = (*Error)(nil)
+
+// SetType 设置错误的类型
+func (msg *Error) SetType(flags ErrorType) *Error {
+	msg.Type = flags
+	return msg
+}
+
+// SetMeta 设置错误的元数据
+func (msg *Error) SetMeta(data any) *Error {
+	msg.Meta = data
+	return msg
+}
+
+// JSON 返回错误的 JSON 表示
+func (msg *Error) JSON() any {
+	jsonData := H{}
+	if msg.Meta != nil {
+		value := reflect.ValueOf(msg.Meta)
+		switch value.Kind() {
+		case reflect.Struct:
+			return msg.Meta
+		case reflect.Map:
+			for _, key := range value.MapKeys() {
+				jsonData[key.String()] = value.MapIndex(key).Interface()
+			}
+		default:
+			jsonData["meta"] = msg.Meta
+		}
+	}
+	if _, ok := jsonData["error"]; !ok {
+		jsonData["error"] = msg.Error()
+	}
+	return jsonData
+}
+
+// MarshalJSON 实现了 json.Marshaller 接口
+func (msg *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(msg.JSON())
+}
+
+// Error 实现了 error 接口
+func (msg Error) Error() string {
+	return msg.Err.Error()
+}
+
+// IsType 判断错误是否属于给定的类型
+func (msg *Error) IsType(flags ErrorType) bool {
+	return (msg.Type & flags) > 0
+}
+
+// Unwrap 返回原始错误，使 errors.Is 和 errors.As 可以正常工作
+func (msg *Error) Unwrap() error {
+	return msg.Err
+}
+
+// ByType 返回给定类型的错误列表
+func (a errorMsgs) ByType(typ ErrorType) errorMsgs {
+	if len(a) == 0 {
+		return nil
+	}
+	if typ == ErrorTypeAny {
+		return a
+	}
+	var result errorMsgs
+	for _, msg := range a {
+		if msg.IsType(typ) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Last 返回列表中的最后一个错误，列表为空时返回 nil
+func (a errorMsgs) Last() *Error {
+	if length := len(a); length > 0 {
+		return a[length-1]
+	}
+	return nil
+}
+
+// Errors 返回所有错误信息的字符串切片
+func (a errorMsgs) Errors() []string {
+	if len(a) == 0 {
+		return nil
+	}
+	errorStrings := make([]string, len(a))
+	for i, err := range a {
+		errorStrings[i] = err.Error()
+	}
+	return errorStrings
+}
+
+// JSON 返回错误列表的 JSON 表示
+func (a errorMsgs) JSON() any {
+	switch length := len(a); length {
+	case 0:
+		return nil
+	case 1:
+		return a.Last().JSON()
+	default:
+		jsonData := make([]any, length)
+		for i, err := range a {
+			jsonData[i] = err.JSON()
+		}
+		return jsonData
+	}
+}
+
+// MarshalJSON 实现了 json.Marshaller 接口
+func (a errorMsgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.JSON())
+}
+
+func (a errorMsgs) String() string {
+	if len(a) == 0 {
+		return ""
+	}
+	var buffer strings.Builder
+	for i, msg := range a {
+		fmt.Fprintf(&buffer, "Error #%02d: %s\n", i+1, msg.Err)
+		if msg.Meta != nil {
+			fmt.Fprintf(&buffer, "     Meta: %v\n", msg.Meta)
+		}
+	}
+	return buffer.String()
+}
@@ -1,7 +1,32 @@
 package gon
 
 import (
+	"fmt"
+	"maps"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+
+	"github.com/stolenzc/gon/internal/bytesconv"
+)
+
+var (
+	default404Body = []byte("404 page not found")     // 默认的 404 响应体
+	default405Body = []byte("405 method not allowed") // 默认的 405 响应体
+)
+
+var mimePlain = []string{"text/plain"}
+
+const defaultMultipartMemory = 32 << 20 // 32 MB
+
+var (
+	regSafePrefix         = regexp.MustCompile("[^a-zA-Z0-9/-]+") // 用于过滤 X-Forwarded-Prefix 中的不安全字符
+	regRemoveRepeatedChar = regexp.MustCompile("/{2,}")           // 用于将连续的多个 "/" 替换为一个
 )
 
 // HandlerFunc 表示一个请求处理函数的类型
@@ -13,31 +38,171 @@ type OptionFunc func(*Engine)
 // HandlerChain 是一个handler函数的切片，用来存储一个请求的处理链
 type HandlerChain []HandlerFunc
 
+// indexOf 返回处理链中第一个和 target 为同一函数值的处理函数的位置，不存在时返回 -1
+func (c HandlerChain) indexOf(target HandlerFunc) int {
+	id := funcIdentity(target)
+	for i, handler := range c {
+		if funcIdentity(handler) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Last 返回处理链中的最后一个处理函数，即路由真正的处理函数，处理链为空时返回 nil
+func (c HandlerChain) Last() HandlerFunc {
+	if length := len(c); length > 0 {
+		return c[length-1]
+	}
+	return nil
+}
+
+// RouteInfo 表示一个已注册路由的信息
+type RouteInfo struct {
+	Method      string      // 请求方式
+	Path        string      // 路由的完整路径模板
+	Handler     string      // 路由处理函数的名称
+	HandlerFunc HandlerFunc // 路由处理函数
+	Group       string      // 注册该路由的路由组的 basePath
+	Host        string      // 通过 Engine.Host 注册的路由的主机名模式，默认路由树中的路由为空字符串
+}
+
+// RoutesInfo 是 RouteInfo 的切片
+type RoutesInfo []RouteInfo
+
+// String 将路由信息按照路由组分组，渲染为便于阅读的表格
+//
+//	[/v1]
+//	  GET     /v1/users/:id   main.getUser
+//	  POST    /v1/users       main.createUser
+func (routes RoutesInfo) String() string {
+	groups := make(map[string]RoutesInfo)
+	for _, route := range routes {
+		key := route.Host + route.Group
+		groups[key] = append(groups[key], route)
+	}
+	groupPaths := make([]string, 0, len(groups))
+	for groupPath := range groups {
+		groupPaths = append(groupPaths, groupPath)
+	}
+	slices.Sort(groupPaths)
+
+	var buf strings.Builder
+	tw := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	for _, groupPath := range groupPaths {
+		fmt.Fprintf(tw, "[%s]\n", groupPath)
+
+		group := groups[groupPath]
+		slices.SortStableFunc(group, func(a, b RouteInfo) int {
+			if c := strings.Compare(a.Path, b.Path); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Method, b.Method)
+		})
+		for _, route := range group {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+		}
+	}
+	_ = tw.Flush()
+	return buf.String()
+}
+
 // Engine 是gon的核心引擎结构体，实现了 http.Handler 接口
 type Engine struct {
-	RouterGroup             // 路由组
-	pool        sync.Pool   // 用于存储 Context 对象的池，减少内存分配和垃圾回收的开销
-	trees       methodTrees // 存储不同 HTTP 方法的路由树
-	maxParams   uint16      // maxParams 用来记录所注册的路由中，最多参数的路由中，参数的个数，主要用于分配 Context 的 Params 数组长度，用于节省内存，防止频繁 GC
-	maxSections uint16		// maxSections 用来记录所注册的路由中，路径最长的分段数量，路径分段是指路径中以 "/" 分割的部分
+	RouterGroup                              // 路由组
+	pool        sync.Pool                    // 用于存储 Context 对象的池，减少内存分配和垃圾回收的开销
+	routes      atomic.Pointer[routeTable]   // 当前的路由表快照，修改路由时会原子地替换为新的快照
+	routesMu    sync.Mutex                   // 保证修改路由的操作串行执行，同时保护 routeGroups 和 namedRoutes
+	serving     atomic.Bool                  // 是否已经开始处理请求，开始处理请求之后修改路由时需要复制路由树
+	noRoute     HandlerChain                 // 通过 NoRoute 设置的 404 处理链
+	noMethod    HandlerChain                 // 通过 NoMethod 设置的 405 处理链
+	allNoRoute  HandlerChain                 // 全局中间件 + noRoute 组合而成的 404 处理链
+	allNoMethod HandlerChain                 // 全局中间件 + noMethod 组合而成的 405 处理链
+	allOptions  HandlerChain                 // 全局中间件 + 自动 OPTIONS 响应组合而成的处理链
+	routeGroups map[string]string            // 记录每个路由所属路由组的 basePath，key 由 routeKey 生成
+	paramTypes  map[string]func(string) bool // 通过 RegisterParamType 注册的参数类型
+	constraints map[string]func(string) bool // 已经解析过的参数约束，key 为约束字符串
+	namedRoutes map[string]*Route            // 通过 Route.Name 命名的路由，key 为路由名称
+
+	// RedirectTrailingSlash 为 true 时，如果当前路由无法匹配，但存在添加或去除末尾 "/" 的路由，则会重定向到该路由
+	// 例如请求 /foo/ 但只存在 /foo 路由时，GET 请求会使用 301 重定向到 /foo，其他请求方式使用 307 重定向
+	RedirectTrailingSlash bool
+
+	// RedirectFixedPath 为 true 时，如果当前路由无法匹配，会尝试修正请求路径后再次查找
+	// 首先会移除多余的路径元素，例如 ../ 和 //，然后进行大小写不敏感的查找
+	// 如果找到了对应的路由，GET 请求会使用 301 重定向到修正后的路径，其他请求方式使用 307 重定向
+	// 例如 /FOO 和 /..//Foo 会被重定向到 /foo，RedirectTrailingSlash 与该选项相互独立
+	RedirectFixedPath bool
+
+	// RemoveExtraSlash 为 true 时，即使请求路径中包含多余的 "/"，也会在清理路径后进行匹配，而不是重定向
+	RemoveExtraSlash bool
+
+	// UseRawPath 为 true 时，会使用 url.RawPath 查找路由参数
+	UseRawPath bool
+
+	// UnescapePathValues 为 true 时，会对路由参数的值进行 URL 解码
+	// 只有 UseRawPath 为 true 时才会生效，因为 url.Path 已经是解码后的路径
+	UnescapePathValues bool
+
+	// HandleHEAD 为 true 时，如果 HEAD 请求没有匹配到路由，会使用对应的 GET 路由处理
+	// 处理函数写入的响应体会被丢弃，但会根据响应体的长度设置 Content-Length 响应头
+	HandleHEAD bool
+
+	// HandleOPTIONS 为 true 时，如果 OPTIONS 请求没有匹配到路由，会自动返回 204 响应
+	// 并在 Allow 响应头中列出该路径注册的所有请求方式，全局中间件（例如 CORS 中间件）仍然会被执行
+	HandleOPTIONS bool
+
+	// HandleMethodNotAllowed 为 true 时，如果当前请求方式无法匹配路由，会检查其他请求方式是否存在该路由
+	// 如果存在，则返回 405 Method Not Allowed，并在 Allow 响应头中列出支持的请求方式，否则返回 404
+	HandleMethodNotAllowed bool
+
+	// MaxMultipartMemory 是解析 multipart/form-data 请求体时使用的最大内存，超出的部分会存储到临时文件中
+	MaxMultipartMemory int64
+
+	// ContextWithFallback 为 true 时，Context 的 Deadline、Done、Err 和 Value 方法会回退到 c.Request.Context()
+	// 为 false 时 Context 不会感知请求的取消和超时，Value 只会查找 Keys
+	ContextWithFallback bool
 }
 
+// 确保 Engine 实现了 IRouter 和 http.Handler 接口
+var (
+	_ IRouter      = (*Engine)(nil)
+	_ http.Handler = (*Engine)(nil)
+)
 
 // New 创建一个新的 Engine 实例，返回指向 Engine 的指针
 func New(opts ...OptionFunc) *Engine {
+	debugPrintWARNINGNew()
 	engine := &Engine{
 		RouterGroup: RouterGroup{
 			Handlers: nil, // 根路由组的处理链为空
 			basePath: "/",
 			root:     true, // 根路由组
 		},
-		trees: make(methodTrees, 0, 9), // 初始化路由树切片，最多存储9种HTTP方法
+		RedirectTrailingSlash: true,
+		RedirectFixedPath:     false,
+		RemoveExtraSlash:      false,
+		UseRawPath:            false,
+		UnescapePathValues:    true,
+		HandleHEAD:            false,
+		HandleOPTIONS:         false,
+		MaxMultipartMemory:    defaultMultipartMemory,
+		routeGroups:           make(map[string]string),
+		namedRoutes:           make(map[string]*Route),
+		paramTypes:            maps.Clone(defaultParamTypes),
+		constraints:           make(map[string]func(string) bool),
 	}
 
 	engine.engine = engine // 设置根路由组 RouterGroup 引擎指针，指向自身
+	engine.routes.Store(&routeTable{
+		trees: make(methodTrees, 0, 9), // 初始化路由树切片，最多存储9种HTTP方法
+	})
+
+	engine.rebuildOptionsHandlers()
 
 	engine.pool.New = func() any {
-		return engine.allocateContext(engine.maxParams)
+		table := engine.routes.Load()
+		return engine.allocateContext(table.maxParams, table.maxSections)
 	}
 	engine.With(opts...)
 	return engine
@@ -45,28 +210,75 @@ func New(opts ...OptionFunc) *Engine {
 
 // Default 返回已附加 Logger 和 Recovery 中间件的 Engine 实例。
 func Default(opts ...OptionFunc) *Engine {
-	// TODO debug 调试信息
+	debugPrintWARNINGDefault()
 	engine := New()
 	// engine.Use(Logger(), Recovery()) // TODO 添加默认的 Logger 和 Recovery 中间件
 	return engine.With(opts...)
 }
 
-// allocateContext
-func (engine *Engine) allocateContext(maxParams uint16) *Context{
+// allocateContext 用于创建一个新的 Context，并按照路由中最多的参数数量和分段数量预分配 Params 和 skippedNodes
+func (engine *Engine) allocateContext(maxParams, maxSections uint16) *Context {
 	v := make(Params, 0, maxParams)
-	// TODO 补全这里的逻辑
-	return &Context{engine: engine, params: &v}
+	skippedNodes := make([]skippedNode, 0, maxSections)
+	return &Context{engine: engine, params: &v, skippedNodes: &skippedNodes}
 }
 
+// NoRoute 用于设置没有匹配到路由时执行的处理链，默认返回 404 状态码
+func (engine *Engine) NoRoute(handlers ...HandlerFunc) {
+	engine.noRoute = handlers
+	engine.rebuild404Handlers()
+}
+
+// NoMethod 用于设置请求方式不被允许时执行的处理链，默认返回 405 状态码，只有 HandleMethodNotAllowed 为 true 时才会生效
+func (engine *Engine) NoMethod(handlers ...HandlerFunc) {
+	engine.noMethod = handlers
+	engine.rebuild405Handlers()
+}
 
 // Use 是 Engine 的方法，用于添加中间件到根路由组的处理链中
+// 全局中间件同样会作用于 404 和 405 的处理链
 func (engine *Engine) Use(middleware ...HandlerFunc) IRoutes {
 	// 将传入的处理函数添加到根路由组的处理链中
 	engine.RouterGroup.Use(middleware...)
-	engine.Handlers = append(engine.Handlers, middleware...)
+	engine.rebuild404Handlers()
+	engine.rebuild405Handlers()
+	engine.rebuildOptionsHandlers()
+	return engine
+}
+
+// Prepend 是 Engine 的方法，用于将中间件插入到根路由组处理链的最前面
+func (engine *Engine) Prepend(middleware ...HandlerFunc) IRoutes {
+	engine.RouterGroup.Prepend(middleware...)
+	engine.rebuild404Handlers()
+	engine.rebuild405Handlers()
+	engine.rebuildOptionsHandlers()
+	return engine
+}
+
+// UseAfter 是 Engine 的方法，用于将中间件插入到根路由组处理链中 target 中间件的后面
+func (engine *Engine) UseAfter(target HandlerFunc, middleware ...HandlerFunc) IRoutes {
+	engine.RouterGroup.UseAfter(target, middleware...)
+	engine.rebuild404Handlers()
+	engine.rebuild405Handlers()
+	engine.rebuildOptionsHandlers()
 	return engine
 }
 
+// rebuild404Handlers 使用全局中间件和 noRoute 重新组合 404 处理链
+func (engine *Engine) rebuild404Handlers() {
+	engine.allNoRoute = engine.combineHandlers(engine.noRoute)
+}
+
+// rebuild405Handlers 使用全局中间件和 noMethod 重新组合 405 处理链
+func (engine *Engine) rebuild405Handlers() {
+	engine.allNoMethod = engine.combineHandlers(engine.noMethod)
+}
+
+// rebuildOptionsHandlers 使用全局中间件和自动 OPTIONS 响应重新组合 OPTIONS 处理链
+func (engine *Engine) rebuildOptionsHandlers() {
+	engine.allOptions = engine.combineHandlers(HandlerChain{serveOptions})
+}
+
 // With 是 Engine 的方法，用于配置引擎的选项，可以传入一个修改配置的函数切片，然后在函数中修改engine的配置
 func (engine *Engine) With(opts ...OptionFunc) *Engine {
 	for _, opt := range opts {
@@ -75,34 +287,318 @@ func (engine *Engine) With(opts ...OptionFunc) *Engine {
 	return engine
 }
 
-
-// addRoute 用于添加路由到 Engine 的路由树 trees 中
-func (engine *Engine) addRoute(method, path string, handlers HandlerChain) {
+// addRoute 用于添加路由到 Engine 的路由树中，host 不为空字符串时添加到该主机名的路由树中
+// 路由可以在处理请求的同时添加，此时会复制路由树，添加完成后原子地替换路由表快照
+// 开始处理请求之前直接修改路由树，注册路由触发 panic 时路由树中可能已经添加了一部分节点
+func (engine *Engine) addRoute(host, method, path string, handlers HandlerChain) {
 	assert1(path[0] == '/', "path must begin with '/'")
 	assert1(method != "", "HTTP method can not be empty")
 	assert1(len(handlers) > 0, "there must be at least one handler")
 
-	// Debug mode print
+	// 包含可选参数的路径会被展开为多个路径，这些路径共享同一个处理链
+	paths := expandOptionalPath(path)
+	engine.updateTree(host, method, func(root *node) (*node, bool) {
+		// 如果没有找到对应的路由树，说明该方法的路由树还未创建，则创建一个新的路由树
+		if root == nil {
+			root = &node{fullPath: "/"}
+		} else {
+			root = engine.mutableTree(root)
+		}
+
+		for _, p := range paths {
+			engine.compileConstraints(p)             // 在插入路由树之前解析参数约束，约束不合法时直接 panic
+			root.addRoute(p, handlers)               // 将路由添加到对应的路由树中
+			root.resolvePath(p, engine.paramMatcher) // 为新插入的带约束的参数节点设置匹配函数
+		}
+		return root, true
+	}, func(table *routeTable, hostParams uint16) {
+		table.addLimits(paths, hostParams)
+	})
+
+	// 在释放 routesMu 之后输出调试信息，DebugPrintRouteFunc 中可以调用 Routes 等需要获取 routesMu 的方法
+	for _, p := range paths {
+		debugPrintRoute(method, p, handlers)
+	}
+}
+
+// Routes 返回所有已注册路由的信息，包括请求方式、路径和处理函数名称等
+func (engine *Engine) Routes() (routes RoutesInfo) {
+	engine.routesMu.Lock()
+	defer engine.routesMu.Unlock()
+
+	table := engine.routes.Load()
+	for _, tree := range table.trees {
+		routes = engine.iterate("", "", tree.method, routes, tree.root)
+	}
+	for _, host := range table.hosts {
+		for _, tree := range host.trees {
+			routes = engine.iterate(host.pattern, "", tree.method, routes, tree.root)
+		}
+	}
+	return routes
+}
+
+// iterate 深度优先遍历路由树，收集所有注册了处理链的节点的路由信息
+func (engine *Engine) iterate(host, path, method string, routes RoutesInfo, root *node) RoutesInfo {
+	path += root.path
+	if len(root.handlers) > 0 {
+		handlerFunc := root.handlers.Last()
+		routes = append(routes, RouteInfo{
+			Method:      method,
+			Path:        path,
+			Handler:     nameOfFunction(handlerFunc),
+			HandlerFunc: handlerFunc,
+			Group:       engine.routeGroups[routeKey(host, method, path)],
+			Host:        host,
+		})
+	}
+	for _, child := range root.children {
+		routes = engine.iterate(host, path, method, routes, child)
+	}
+	return routes
+}
+
+// routeKey 返回由请求方式、主机名和路径组成的路由唯一标识
+func routeKey(host, method, path string) string {
+	return method + " " + host + path
+}
+
+// ServeHTTP 实现了 http.Handler 接口，从 engine.pool 中取出 Context 处理请求，处理完成后放回 engine.pool
+// 调试模式下处理完成的 Context 会被标记为已释放且不再放回 engine.pool，请求结束后继续使用该 Context 会触发 panic
+func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !engine.serving.Load() {
+		engine.startServing()
+	}
+
+	c := engine.pool.Get().(*Context)
+	c.writermem.reset(w)
+	c.Request = req
+	c.reset()
+
+	engine.handleHTTPRequest(c)
+
+	if IsDebugging() {
+		c.release()
+		return
+	}
+	engine.pool.Put(c)
+}
 
-	// 获取对应的 HTTP 方法的路由压缩前缀树
-	root := engine.trees.get(method)
+// HandleContext 用于将一个已经被处理过的 Context 重新分发处理，常用于在处理函数中将请求内部重定向到其他路径
+// 使用前需要先修改 c.Request.URL.Path
+func (engine *Engine) HandleContext(c *Context) {
+	c.checkReleased()
+	oldIndexValue := c.index
+	c.reset()
+	engine.handleHTTPRequest(c)
+
+	c.index = oldIndexValue
+}
 
-	// 如果没有找到对应的路由树，说明该方法的路由树还未创建，则创建一个新的路由树
+// handleHTTPRequest 根据请求方式和路径查找路由，并执行匹配到的处理链
+func (engine *Engine) handleHTTPRequest(c *Context) {
+	httpMethod := c.Request.Method
+	rPath := c.Request.URL.Path
+	unescape := false
+	if engine.UseRawPath && len(c.Request.URL.RawPath) > 0 {
+		rPath = c.Request.URL.RawPath
+		unescape = engine.UnescapePathValues
+	}
+
+	if engine.RemoveExtraSlash {
+		rPath = cleanPath(rPath)
+	}
+
+	// 请求的主机名和通过 Host 注册的主机名匹配时使用该主机名的路由树，否则使用默认的路由树
+	table := engine.routes.Load()
+	trees, host := table.trees, (*hostRoute)(nil)
+	if len(table.hosts) > 0 {
+		if host = table.matchHost(c.Request.Host); host != nil {
+			trees = host.trees
+		}
+	}
+
+	// 查找请求方式对应的路由树，然后在路由树中查找路由
+	if root := trees.get(httpMethod); root != nil {
+		value := root.getValue(rPath, c.params, c.skippedNodes, unescape)
+		if value.params != nil {
+			c.Params = *value.params
+		}
+		if value.handlers != nil {
+			if host != nil {
+				host.bindParams(c)
+			}
+			c.handlers = value.handlers
+			c.fullPath = value.fullPath
+			c.Next()
+			c.writermem.WriteHeaderNow()
+			return
+		}
+		if httpMethod != http.MethodConnect && rPath != "/" {
+			if value.tsr && engine.RedirectTrailingSlash {
+				redirectTrailingSlash(c)
+				return
+			}
+			if engine.RedirectFixedPath && redirectFixedPath(c, root, engine.RedirectTrailingSlash) {
+				return
+			}
+		}
+	}
+
+	if httpMethod == http.MethodHead && engine.HandleHEAD && engine.handleHeadAsGet(c, trees, host, rPath, unescape) {
+		return
+	}
+
+	if httpMethod == http.MethodOptions && engine.HandleOPTIONS && engine.handleOptions(c, trees, rPath) {
+		return
+	}
+
+	if engine.HandleMethodNotAllowed {
+		// 根据 RFC 7231 6.5.5 节的规定，405 响应必须包含 Allow 响应头，列出目标资源支持的请求方式
+		if allowed := engine.allowedMethods(c, trees, httpMethod, rPath); len(allowed) > 0 {
+			c.handlers = engine.allNoMethod
+			c.writermem.Header().Set("Allow", strings.Join(allowed, ", "))
+			serveError(c, http.StatusMethodNotAllowed, default405Body)
+			return
+		}
+	}
+
+	c.handlers = engine.allNoRoute
+	serveError(c, http.StatusNotFound, default404Body)
+}
+
+// allowedMethods 返回路由树 trees 中除 httpMethod 之外，所有注册了 rPath 路由的请求方式
+func (engine *Engine) allowedMethods(c *Context, trees methodTrees, httpMethod, rPath string) []string {
+	var allowed []string
+	for _, tree := range trees {
+		if tree.method == httpMethod {
+			continue
+		}
+		// 每次查找前清空上一次查找遗留的跳过节点，避免回溯到其他路由树的节点上
+		*c.skippedNodes = (*c.skippedNodes)[:0]
+		if value := tree.root.getValue(rPath, nil, c.skippedNodes, false); value.handlers != nil {
+			allowed = append(allowed, tree.method)
+		}
+	}
+
+	// 开启了 HandleHEAD 时，存在 GET 路由就意味着同样支持 HEAD 请求
+	if engine.HandleHEAD && httpMethod != http.MethodHead &&
+		slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	return allowed
+}
+
+// handleHeadAsGet 使用 GET 路由处理 HEAD 请求，丢弃响应体但保留 Content-Length，没有匹配到 GET 路由时返回 false
+func (engine *Engine) handleHeadAsGet(c *Context, trees methodTrees, host *hostRoute, rPath string, unescape bool) bool {
+	root := trees.get(http.MethodGet)
 	if root == nil {
-		root = new(node)
-		root.fullPath = path
-		engine.trees = append(engine.trees, methodTree{method: method, root: root})
+		return false
+	}
+
+	*c.params = (*c.params)[:0]
+	*c.skippedNodes = (*c.skippedNodes)[:0]
+	value := root.getValue(rPath, c.params, c.skippedNodes, unescape)
+	if value.handlers == nil {
+		return false
+	}
+	if value.params != nil {
+		c.Params = *value.params
+	}
+	if host != nil {
+		host.bindParams(c)
+	}
+
+	hw := &headResponseWriter{ResponseWriter: c.writermem.ResponseWriter}
+	c.writermem.ResponseWriter = hw
+	c.handlers = value.handlers
+	c.fullPath = value.fullPath
+	c.Next()
+	c.writermem.WriteHeaderNow()
+	hw.flush()
+	return true
+}
+
+// handleOptions 自动响应 OPTIONS 请求，在 Allow 响应头中列出该路径支持的所有请求方式，路径不存在时返回 false
+func (engine *Engine) handleOptions(c *Context, trees methodTrees, rPath string) bool {
+	allowed := engine.allowedMethods(c, trees, http.MethodOptions, rPath)
+	if len(allowed) == 0 {
+		return false
+	}
+
+	allowed = append(allowed, http.MethodOptions)
+	c.writermem.Header().Set("Allow", strings.Join(allowed, ", "))
+	c.handlers = engine.allOptions
+	c.Next()
+	c.writermem.WriteHeaderNow()
+	return true
+}
+
+// serveOptions 是自动 OPTIONS 响应的处理函数，Allow 响应头已经在 handleOptions 中设置
+func serveOptions(c *Context) {
+	c.Status(http.StatusNoContent)
+}
+
+// serveError 执行 Context 中的处理链，如果处理链中没有写入响应，则写入默认的错误信息
+func serveError(c *Context, code int, defaultMessage []byte) {
+	c.writermem.status = code
+	c.Next()
+	if c.writermem.Written() {
+		return
+	}
+	if c.writermem.Status() == code {
+		c.writermem.Header()["Content-Type"] = mimePlain
+		_, err := c.Writer.Write(defaultMessage)
+		if err != nil {
+			debugPrint("cannot write message to writer during serve error: %v", err)
+		}
+		return
 	}
+	c.writermem.WriteHeaderNow()
+}
 
-	root.addRoute(path, handlers) // 将路由添加到对应的路由树中
+// redirectTrailingSlash 将请求重定向到添加或去除末尾 "/" 的路径
+func redirectTrailingSlash(c *Context) {
+	req := c.Request
+	p := req.URL.Path
+	if prefix := path.Clean(c.Request.Header.Get("X-Forwarded-Prefix")); prefix != "." {
+		prefix = regSafePrefix.ReplaceAllString(prefix, "")
+		prefix = regRemoveRepeatedChar.ReplaceAllString(prefix, "/")
 
-	// 检查路径中参数的数量是否超出记录的最大参数数量
-	if paramsCount := countParams(path); paramsCount > engine.maxParams {
-		engine.maxParams = paramsCount // 更新最大参数数量
+		p = prefix + "/" + req.URL.Path
+	}
+	req.URL.Path = p + "/"
+	if length := len(p); length > 1 && p[length-1] == '/' {
+		req.URL.Path = p[:length-1]
 	}
+	redirectRequest(c)
+}
+
+// redirectFixedPath 清理请求路径并进行大小写不敏感的查找，找到对应的路由时重定向到修正后的路径
+func redirectFixedPath(c *Context, root *node, trailingSlash bool) bool {
+	req := c.Request
+	rPath := req.URL.Path
 
-	// 检查路径中分段的数量是否超出记录的最大分段数量
-	if sectionsCount := countSections(path); sectionsCount > engine.maxSections {
-		engine.maxSections = sectionsCount // 更新最大分段数量
+	if fixedPath, ok := root.findCaseInsensitivePath(cleanPath(rPath), trailingSlash); ok {
+		req.URL.Path = bytesconv.BytesToString(fixedPath)
+		redirectRequest(c)
+		return true
 	}
-}
\ No newline at end of file
+	return false
+}
+
+// redirectRequest 将请求重定向到 c.Request.URL
+// GET 请求使用 301 永久重定向，其他请求方式使用 307 临时重定向，以保证请求方式和请求体不会被客户端改变
+func redirectRequest(c *Context) {
+	req := c.Request
+	rPath := req.URL.Path
+	rURL := req.URL.String()
+
+	code := http.StatusMovedPermanently
+	if req.Method != http.MethodGet {
+		code = http.StatusTemporaryRedirect
+	}
+	debugPrint("redirecting request %d: %s --> %s", code, rPath, rURL)
+	http.Redirect(c.Writer, req, rURL, code)
+	c.writermem.WriteHeaderNow()
+}
@@ -0,0 +1,405 @@
+package gon
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// performRequest 使用 engine 处理一个请求，返回记录的响应
+func performRequest(engine http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+// catchPanic 执行 testFunc 并返回其中触发的 panic，没有触发 panic 时返回 nil
+func catchPanic(testFunc func()) (recv any) {
+	defer func() {
+		recv = recover()
+	}()
+
+	testFunc()
+	return
+}
+
+func TestHandleContext(t *testing.T) {
+	router := New()
+	var handled []string
+	router.GET("/old/:a/:b", func(c *Context) {
+		handled = append(handled, c.FullPath())
+		c.Request.URL.Path = "/new/1"
+		router.HandleContext(c)
+	})
+	router.GET("/new/:id", func(c *Context) {
+		handled = append(handled, c.FullPath())
+		// 重新分发前的参数会被清空，只保留新路由的参数
+		if got := c.Params; len(got) != 1 || got[0] != (Param{Key: "id", Value: "1"}) {
+			t.Errorf("params after HandleContext = %v, want [{id 1}]", got)
+		}
+		c.Writer.WriteHeader(http.StatusAccepted)
+	})
+
+	w := performRequest(router, http.MethodGet, "/old/x/y")
+	if want := []string{"/old/:a/:b", "/new/:id"}; len(handled) != 2 || handled[0] != want[0] || handled[1] != want[1] {
+		t.Errorf("handled %v, want %v", handled, want)
+	}
+	if w.Code != http.StatusAccepted {
+		t.Errorf("got %d, want %d", w.Code, http.StatusAccepted)
+	}
+}
+
+func TestServeHTTPResetsPooledContext(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {
+		_ = c.Error(errors.New("failed"))
+		c.Set("user", "gon")
+	})
+	router.GET("/", func(c *Context) {
+		// 从 pool 中取出的 Context 不会保留上一个请求的状态
+		if len(c.Params) != 0 || len(*c.params) != 0 || len(c.Errors) != 0 || c.Keys != nil ||
+			c.FullPath() != "/" || len(c.handlers) != 1 {
+			t.Errorf("got params %v full path %q handlers %d errors %v keys %v", c.Params, c.FullPath(), len(c.handlers), c.Errors, c.Keys)
+		}
+	})
+
+	for i := 0; i < 3; i++ {
+		performRequest(router, http.MethodGet, "/users/42")
+		if w := performRequest(router, http.MethodGet, "/"); w.Code != http.StatusOK {
+			t.Errorf("GET /: got %d, want 200", w.Code)
+		}
+	}
+}
+
+func TestNoRoute(t *testing.T) {
+	router := New()
+	router.GET("/users", func(c *Context) {})
+
+	w := performRequest(router, http.MethodGet, "/missing")
+	if w.Code != http.StatusNotFound || w.Body.String() != "404 page not found" || w.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("default 404: got %d %q %q", w.Code, w.Body.String(), w.Header().Get("Content-Type"))
+	}
+
+	// 请求方式不匹配时，没有开启 HandleMethodNotAllowed 同样返回 404
+	if w := performRequest(router, http.MethodPost, "/users"); w.Code != http.StatusNotFound {
+		t.Errorf("POST /users: got %d, want 404", w.Code)
+	}
+
+	// 全局中间件在 NoRoute 之前和之后添加都会作用于 404 处理链
+	router.Use(traceMiddleware("before"))
+	router.NoRoute(func(c *Context) {
+		c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	})
+	router.Use(traceMiddleware("after"))
+
+	w = performRequest(router, http.MethodGet, "/missing")
+	if w.Code != http.StatusNotFound || strings.TrimSpace(w.Body.String()) != `{"error":"not found"}` {
+		t.Errorf("custom 404: got %d %q", w.Code, w.Body.String())
+	}
+	if got := strings.Join(w.Header().Values("X-Trace"), ","); got != "before,after" {
+		t.Errorf("custom 404: got trace %q, want before,after", got)
+	}
+
+	// NoRoute 修改了状态码但没有写入响应体时，只写入状态码
+	router.NoRoute(func(c *Context) { c.Status(http.StatusTeapot) })
+	if w := performRequest(router, http.MethodGet, "/missing"); w.Code != http.StatusTeapot || w.Body.Len() != 0 {
+		t.Errorf("NoRoute with status: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestNoMethod(t *testing.T) {
+	router := New()
+	router.HandleMethodNotAllowed = true
+	router.GET("/users/:id", func(c *Context) {})
+	router.DELETE("/users/:id", func(c *Context) {})
+	router.POST("/users", func(c *Context) {})
+
+	for _, tt := range []struct {
+		method, path string
+		code         int
+		allow        string
+	}{
+		{http.MethodPut, "/users/1", http.StatusMethodNotAllowed, "GET, DELETE"},
+		{http.MethodGet, "/users", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodPut, "/missing", http.StatusNotFound, ""},
+		{http.MethodGet, "/users/1", http.StatusOK, ""},
+	} {
+		w := performRequest(router, tt.method, tt.path)
+		if w.Code != tt.code || w.Header().Get("Allow") != tt.allow {
+			t.Errorf("%s %s: got %d %q, want %d %q", tt.method, tt.path, w.Code, w.Header().Get("Allow"), tt.code, tt.allow)
+		}
+	}
+
+	w := performRequest(router, http.MethodPut, "/users/1")
+	if w.Body.String() != "405 method not allowed" {
+		t.Errorf("default 405 body: got %q", w.Body.String())
+	}
+
+	router.Use(traceMiddleware("global"))
+	router.NoMethod(func(c *Context) {
+		c.JSON(http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	})
+	w = performRequest(router, http.MethodPut, "/users/1")
+	if w.Code != http.StatusMethodNotAllowed || strings.TrimSpace(w.Body.String()) != `{"error":"method not allowed"}` ||
+		w.Header().Get("Allow") != "GET, DELETE" || w.Header().Get("X-Trace") != "global" {
+		t.Errorf("custom 405: got %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+}
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	for _, tt := range []struct {
+		trailingSlash bool
+		method, path  string
+		code          int
+		location      string
+	}{
+		{true, http.MethodGet, "/foo/", http.StatusMovedPermanently, "/foo"},
+		{true, http.MethodGet, "/bar", http.StatusMovedPermanently, "/bar/"},
+		{true, http.MethodPost, "/foo/", http.StatusTemporaryRedirect, "/foo"},
+		{true, http.MethodPut, "/bar", http.StatusTemporaryRedirect, "/bar/"},
+		{true, http.MethodGet, "/foo", http.StatusOK, ""},
+		{false, http.MethodGet, "/foo/", http.StatusNotFound, ""},
+		{false, http.MethodPost, "/bar", http.StatusNotFound, ""},
+	} {
+		router := New()
+		router.RedirectTrailingSlash = tt.trailingSlash
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut} {
+			router.Handler(method, "/foo", func(c *Context) {})
+			router.Handler(method, "/bar/", func(c *Context) {})
+		}
+
+		w := performRequest(router, tt.method, tt.path)
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("RedirectTrailingSlash=%v %s %s: got %d %q, want %d %q",
+				tt.trailingSlash, tt.method, tt.path, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+}
+
+func TestRedirectFixedPath(t *testing.T) {
+	for _, tt := range []struct {
+		trailingSlash bool
+		path          string
+		code          int
+		location      string
+	}{
+		{true, "/FOO", http.StatusMovedPermanently, "/foo"},
+		{true, "/FOO/", http.StatusMovedPermanently, "/foo"},
+		{true, "/..//Foo", http.StatusMovedPermanently, "/foo"},
+		{false, "/FOO", http.StatusMovedPermanently, "/foo"},
+		{false, "/FOO/", http.StatusNotFound, ""},
+		{false, "/foo/", http.StatusNotFound, ""},
+	} {
+		engine := New()
+		engine.RedirectTrailingSlash = tt.trailingSlash
+		engine.RedirectFixedPath = true
+		engine.GET("/foo", func(c *Context) {})
+
+		w := performRequest(engine, http.MethodGet, tt.path)
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("RedirectTrailingSlash=%v GET %s: got %d %q, want %d %q",
+				tt.trailingSlash, tt.path, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+}
+
+func TestRedirectFixedPathSuffixParams(t *testing.T) {
+	engine := New()
+	engine.RedirectFixedPath = true
+	engine.GET("/v:major.:minor/status", func(c *Context) {})
+	engine.GET("/files/:name.:ext<[a-z]+>", func(c *Context) {})
+
+	for path, location := range map[string]string{
+		"/v1.2/STATUS":        "/v1.2/status",
+		"/FILES/Report.pdf":   "/files/Report.pdf",
+		"/Files/report.v2.gz": "/files/report.v2.gz",
+	} {
+		w := performRequest(engine, http.MethodGet, path)
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != location {
+			t.Errorf("GET %s: got %d %q, want 301 %q", path, w.Code, w.Header().Get("Location"), location)
+		}
+	}
+}
+
+func TestRedirectFixedPathMethods(t *testing.T) {
+	for _, tt := range []struct {
+		fixedPath    bool
+		method, path string
+		code         int
+		location     string
+	}{
+		{true, http.MethodGet, "/FOO", http.StatusMovedPermanently, "/foo"},
+		{true, http.MethodPost, "/FOO", http.StatusTemporaryRedirect, "/foo"},
+		{true, http.MethodPost, "/..//foo", http.StatusTemporaryRedirect, "/foo"},
+		{false, http.MethodGet, "/FOO", http.StatusNotFound, ""},
+		{false, http.MethodGet, "/..//foo", http.StatusNotFound, ""},
+	} {
+		router := New()
+		router.RedirectFixedPath = tt.fixedPath
+		router.GET("/foo", func(c *Context) {})
+		router.POST("/foo", func(c *Context) {})
+
+		w := performRequest(router, tt.method, tt.path)
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("RedirectFixedPath=%v %s %s: got %d %q, want %d %q",
+				tt.fixedPath, tt.method, tt.path, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+}
+
+func TestRemoveExtraSlash(t *testing.T) {
+	for _, tt := range []struct {
+		removeExtraSlash bool
+		path             string
+		code             int
+	}{
+		{true, "//a///b", http.StatusOK},
+		{true, "/a/b", http.StatusOK},
+		{false, "//a///b", http.StatusNotFound},
+	} {
+		router := New()
+		router.RemoveExtraSlash = tt.removeExtraSlash
+		var fullPath string
+		router.GET("/a/b", func(c *Context) { fullPath = c.FullPath() })
+
+		w := performRequest(router, http.MethodGet, tt.path)
+		if w.Code != tt.code || (tt.code == http.StatusOK) != (fullPath == "/a/b") {
+			t.Errorf("RemoveExtraSlash=%v GET %s: got %d %q, want %d", tt.removeExtraSlash, tt.path, w.Code, fullPath, tt.code)
+		}
+	}
+}
+
+func TestHandleHEAD(t *testing.T) {
+	router := New()
+	router.HandleHEAD = true
+	router.GET("/data/:name", func(c *Context) {
+		c.Writer.Header().Set("X-Full-Path", c.FullPath())
+		_, _ = c.Writer.Write([]byte("hello"))
+	})
+	router.GET("/created", func(c *Context) {
+		c.Writer.Header().Set("Content-Length", "42")
+		c.Status(http.StatusCreated)
+	})
+	router.GET("/explicit", func(c *Context) {})
+	router.HEAD("/explicit", func(c *Context) { c.Status(http.StatusAccepted) })
+
+	for _, tt := range []struct {
+		path          string
+		code          int
+		contentLength string
+		fullPath      string
+	}{
+		{"/data/gon", http.StatusOK, "5", "/data/:name"},
+		{"/created", http.StatusCreated, "42", ""}, // 处理函数设置的 Content-Length 不会被覆盖
+		{"/explicit", http.StatusAccepted, "", ""}, // 注册了 HEAD 路由时不使用 GET 路由
+		{"/missing", http.StatusNotFound, "", ""},
+	} {
+		w := performRequest(router, http.MethodHead, tt.path)
+		if w.Code != tt.code || w.Header().Get("Content-Length") != tt.contentLength || w.Header().Get("X-Full-Path") != tt.fullPath {
+			t.Errorf("HEAD %s: got %d %q %q, want %d %q %q", tt.path, w.Code, w.Header().Get("Content-Length"),
+				w.Header().Get("X-Full-Path"), tt.code, tt.contentLength, tt.fullPath)
+		}
+		if tt.code != http.StatusNotFound && w.Body.Len() != 0 {
+			t.Errorf("HEAD %s: got body %q", tt.path, w.Body.String())
+		}
+	}
+
+	// 关闭 HandleHEAD 时 HEAD 请求不会使用 GET 路由，开启 HandleMethodNotAllowed 后 GET 路由不会隐含 HEAD
+	router.HandleHEAD = false
+	router.HandleMethodNotAllowed = true
+	if w := performRequest(router, http.MethodHead, "/data/gon"); w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET" {
+		t.Errorf("HEAD without HandleHEAD: got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	router.HandleHEAD = true
+	if w := performRequest(router, http.MethodPost, "/data/gon"); w.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("POST with HandleHEAD: got Allow %q, want GET, HEAD", w.Header().Get("Allow"))
+	}
+}
+
+func TestHandleOPTIONS(t *testing.T) {
+	router := New()
+	router.HandleOPTIONS = true
+	router.Use(traceMiddleware("global"))
+	router.GET("/users/:id", func(c *Context) {})
+	router.DELETE("/users/:id", func(c *Context) {})
+	router.GET("/custom", func(c *Context) {})
+	router.OPTIONS("/custom", func(c *Context) { c.Status(http.StatusOK) })
+
+	w := performRequest(router, http.MethodOptions, "/users/1")
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "GET, DELETE, OPTIONS" || w.Header().Get("X-Trace") != "global" {
+		t.Errorf("OPTIONS /users/1: got %d %v", w.Code, w.Header())
+	}
+
+	// 注册了 OPTIONS 路由时使用注册的路由
+	if w := performRequest(router, http.MethodOptions, "/custom"); w.Code != http.StatusOK || w.Header().Get("Allow") != "" {
+		t.Errorf("OPTIONS /custom: got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	if w := performRequest(router, http.MethodOptions, "/missing"); w.Code != http.StatusNotFound {
+		t.Errorf("OPTIONS /missing: got %d, want 404", w.Code)
+	}
+
+	router.HandleHEAD = true
+	if w := performRequest(router, http.MethodOptions, "/users/1"); w.Header().Get("Allow") != "GET, DELETE, HEAD, OPTIONS" {
+		t.Errorf("OPTIONS with HandleHEAD: got Allow %q", w.Header().Get("Allow"))
+	}
+
+	router.HandleOPTIONS = false
+	if w := performRequest(router, http.MethodOptions, "/users/1"); w.Code != http.StatusNotFound {
+		t.Errorf("OPTIONS without HandleOPTIONS: got %d, want 404", w.Code)
+	}
+}
+
+func listUsers(c *Context) {}
+
+func showUser(c *Context) {}
+
+func TestRoutes(t *testing.T) {
+	router := New()
+	router.GET("/", showUser)
+	v1 := router.Group("/v1", traceMiddleware("v1"))
+	v1.GET("/users", listUsers)
+	v1.GET("/users/:id", showUser)
+	v1.POST("/users", listUsers)
+	router.Host("api.example.com").GET("/status", showUser)
+
+	want := RoutesInfo{
+		{Method: http.MethodGet, Path: "/", Handler: "github.com/stolenzc/gon.showUser", Group: "/"},
+		{Method: http.MethodGet, Path: "/v1/users", Handler: "github.com/stolenzc/gon.listUsers", Group: "/v1"},
+		{Method: http.MethodGet, Path: "/v1/users/:id", Handler: "github.com/stolenzc/gon.showUser", Group: "/v1"},
+		{Method: http.MethodPost, Path: "/v1/users", Handler: "github.com/stolenzc/gon.listUsers", Group: "/v1"},
+		{Method: http.MethodGet, Path: "/status", Handler: "github.com/stolenzc/gon.showUser", Group: "/", Host: "api.example.com"},
+	}
+	routes := router.Routes()
+	if len(routes) != len(want) {
+		t.Fatalf("got %d routes, want %d: %v", len(routes), len(want), routes)
+	}
+	for _, w := range want {
+		i := slices.IndexFunc(routes, func(r RouteInfo) bool {
+			return r.Method == w.Method && r.Path == w.Path && r.Host == w.Host
+		})
+		if i < 0 {
+			t.Errorf("route %s %s%s not found", w.Method, w.Host, w.Path)
+			continue
+		}
+		got := routes[i]
+		if got.Handler != w.Handler || got.Group != w.Group || got.HandlerFunc == nil {
+			t.Errorf("route %s %s%s: got handler %q group %q, want %q %q", w.Method, w.Host, w.Path, got.Handler, got.Group, w.Handler, w.Group)
+		}
+	}
+
+	// 路由按照主机名和路由组分组，组内按照路径和请求方式排序，每个分组单独对齐
+	wantString := "[/]\n" +
+		"  GET   /   github.com/stolenzc/gon.showUser\n" +
+		"[/v1]\n" +
+		"  GET    /v1/users       github.com/stolenzc/gon.listUsers\n" +
+		"  POST   /v1/users       github.com/stolenzc/gon.listUsers\n" +
+		"  GET    /v1/users/:id   github.com/stolenzc/gon.showUser\n" +
+		"[api.example.com/]\n" +
+		"  GET   /status   github.com/stolenzc/gon.showUser\n"
+	if got := routes.String(); got != wantString {
+		t.Errorf("RoutesInfo.String:\n%s\nwant:\n%s", got, wantString)
+	}
+}
@@ -0,0 +1,129 @@
+package gon
+
+import (
+	"slices"
+	"strings"
+)
+
+// hostRoute 表示一个通过 Engine.Host 注册的主机名，每个主机名拥有独立的路由树
+type hostRoute struct {
+	pattern string      // 注册时使用的主机名模式，例如 ":tenant.example.com"
+	labels  []string    // 主机名模式按照 "." 分割后的标签，以 ':' 开头的标签为参数
+	params  uint16      // 主机名模式中参数的数量
+	trees   methodTrees // 该主机名下的路由树
+}
+
+// Host 用于创建一个只匹配指定主机名的路由组，该路由组及其子路由组注册的路由存储在该主机名独立的路由树中
+// 主机名中以 ':' 开头的标签为参数，匹配主机名中的一个标签，参数值可以通过 Context.Param 获取
+// 请求的主机名会忽略端口并且不区分大小写，没有主机名匹配时使用 Engine 默认的路由树
+// 匹配时不包含参数的主机名优先，多次使用相同的主机名会共享同一组路由树
+//
+//	api := router.Host("api.example.com")
+//	api.GET("/users", listUsers)
+//
+//	tenant := router.Host(":tenant.example.com")
+//	tenant.GET("/", func(c *gon.Context) {
+//	    c.Param("tenant") // 请求 acme.example.com 时为 "acme"
+//	})
+func (engine *Engine) Host(pattern string, handlers ...HandlerFunc) *RouterGroup {
+	newHostRoute(pattern) // 提前检查主机名模式是否合法
+	return &RouterGroup{
+		Handlers: engine.combineHandlers(handlers),
+		basePath: "/",
+		engine:   engine,
+		host:     pattern,
+	}
+}
+
+// newHostRoute 解析主机名模式并创建一个没有路由的 hostRoute，主机名模式不合法时会触发 panic
+func newHostRoute(pattern string) *hostRoute {
+	h := &hostRoute{pattern: pattern, labels: strings.Split(pattern, ".")}
+	for _, label := range h.labels {
+		assert1(label != "" && label != ":", "invalid label in host '"+pattern+"'")
+		if label[0] == ':' {
+			h.params++
+		}
+	}
+	return h
+}
+
+// withHost 返回一个 hosts 的副本，副本中主机名模式为 pattern 的 hostRoute 也是一个副本，可以安全地修改它的路由树
+// pattern 不存在时会创建一个新的 hostRoute，不包含参数的主机名排在前面，优先进行匹配
+func withHost(hosts []*hostRoute, pattern string) ([]*hostRoute, *hostRoute) {
+	for i, h := range hosts {
+		if strings.EqualFold(h.pattern, pattern) {
+			newHosts := slices.Clone(hosts)
+			cp := *h
+			newHosts[i] = &cp
+			return newHosts, &cp
+		}
+	}
+
+	h := newHostRoute(pattern)
+	i := len(hosts)
+	if h.params == 0 {
+		if j := slices.IndexFunc(hosts, func(h *hostRoute) bool { return h.params > 0 }); j >= 0 {
+			i = j
+		}
+	}
+	return slices.Insert(slices.Clone(hosts), i, h), h
+}
+
+// matchHost 返回第一个和请求的主机名匹配的 hostRoute，没有匹配时返回 nil
+func (table *routeTable) matchHost(host string) *hostRoute {
+	host = stripHostPort(host)
+	for _, h := range table.hosts {
+		if h.match(host, nil) {
+			return h
+		}
+	}
+	return nil
+}
+
+// match 判断主机名是否和主机名模式匹配，params 不为 nil 时会将参数追加到 params 中
+func (h *hostRoute) match(host string, params *Params) bool {
+	for i, label := range h.labels {
+		var value string
+		if i == len(h.labels)-1 {
+			value, host = host, ""
+		} else {
+			end := strings.IndexByte(host, '.')
+			if end < 0 {
+				return false
+			}
+			value, host = host[:end], host[end+1:]
+		}
+
+		if label[0] != ':' {
+			if !strings.EqualFold(label, value) {
+				return false
+			}
+			continue
+		}
+		if value == "" {
+			return false
+		}
+		if params != nil {
+			*params = append(*params, Param{Key: label[1:], Value: strings.ToLower(value)})
+		}
+	}
+	return true
+}
+
+// bindParams 将请求主机名中的参数追加到 Context 已经匹配到的路径参数后面
+func (h *hostRoute) bindParams(c *Context) {
+	if h.params == 0 {
+		return
+	}
+	*c.params = (*c.params)[:len(c.Params)]
+	h.match(stripHostPort(c.Request.Host), c.params)
+	c.Params = *c.params
+}
+
+// stripHostPort 去除主机名中的端口，支持 IPv6 地址，例如 "[::1]:8080" 返回 "[::1]"
+func stripHostPort(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i > strings.LastIndexByte(host, ']') {
+		return host[:i]
+	}
+	return host
+}
@@ -0,0 +1,122 @@
+package gon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// performHostRequest 使用 engine 处理一个指定主机名的请求，返回记录的响应
+func performHostRequest(engine http.Handler, method, host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = host
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestHost(t *testing.T) {
+	router := New()
+	router.GET("/status", func(c *Context) { c.Writer.Header().Set("X-Tree", "default") })
+	router.Host("api.example.com").GET("/status", func(c *Context) { c.Writer.Header().Set("X-Tree", "api") })
+	router.Host(":tenant.example.com").GET("/status", func(c *Context) {
+		c.Writer.Header().Set("X-Tree", "tenant:"+c.Param("tenant"))
+	})
+	router.Host(":app.:region.cloud.test").GET("/apps/:id", func(c *Context) {
+		c.Writer.Header().Set("X-Tree", c.Param("id")+"@"+c.Param("app")+"."+c.Param("region"))
+	})
+
+	for _, tt := range []struct {
+		host, path string
+		code       int
+		tree       string
+	}{
+		{"api.example.com", "/status", http.StatusOK, "api"},
+		{"API.Example.COM:8080", "/status", http.StatusOK, "api"}, // 忽略端口和大小写
+		{"acme.example.com", "/status", http.StatusOK, "tenant:acme"},
+		{"ACME.example.com:443", "/status", http.StatusOK, "tenant:acme"}, // 参数值转换为小写
+		{"web.eu.cloud.test", "/apps/42", http.StatusOK, "42@web.eu"},
+		{"example.com", "/status", http.StatusOK, "default"},      // 没有主机名匹配时使用默认的路由树
+		{"a.b.example.com", "/status", http.StatusOK, "default"},  // 参数只匹配一个标签
+		{"[::1]:8080", "/status", http.StatusOK, "default"},       // IPv6 地址
+		{"api.example.com", "/apps/42", http.StatusNotFound, ""},  // 匹配到主机名后不会回退到默认的路由树
+		{"web.eu.cloud.test", "/status", http.StatusNotFound, ""}, // 主机名的路由树中没有该路由
+		{"api.example.com.evil.test", "/status", http.StatusOK, "default"},
+	} {
+		w := performHostRequest(router, http.MethodGet, tt.host, tt.path)
+		if w.Code != tt.code || w.Header().Get("X-Tree") != tt.tree {
+			t.Errorf("GET %s%s: got %d %q, want %d %q", tt.host, tt.path, w.Code, w.Header().Get("X-Tree"), tt.code, tt.tree)
+		}
+	}
+}
+
+func TestHostSharedTrees(t *testing.T) {
+	router := New()
+	router.Host("API.example.com").GET("/a", func(c *Context) {})
+	router.Host("api.example.com").GET("/b", func(c *Context) {})
+
+	for _, path := range []string{"/a", "/b"} {
+		if w := performHostRequest(router, http.MethodGet, "api.example.com", path); w.Code != http.StatusOK {
+			t.Errorf("GET %s: got %d, want 200", path, w.Code)
+		}
+	}
+	if hosts := router.routes.Load().hosts; len(hosts) != 1 {
+		t.Errorf("got %d host routes, want 1", len(hosts))
+	}
+}
+
+func TestHostPriority(t *testing.T) {
+	router := New()
+	router.Host(":tenant.example.com").GET("/", func(c *Context) { c.Writer.Header().Set("X-Tree", "tenant") })
+	router.Host("www.example.com").GET("/", func(c *Context) { c.Writer.Header().Set("X-Tree", "www") })
+
+	// 不包含参数的主机名优先匹配，和注册的顺序无关
+	if w := performHostRequest(router, http.MethodGet, "www.example.com", "/"); w.Header().Get("X-Tree") != "www" {
+		t.Errorf("got tree %q, want www", w.Header().Get("X-Tree"))
+	}
+}
+
+func TestHostParamsCountTowardsMaxParams(t *testing.T) {
+	router := New()
+	router.GET("/:a", func(c *Context) {})
+	router.Host(":x.:y.example.com").GET("/:a/:b", func(c *Context) {
+		if len(c.Params) != 4 {
+			t.Errorf("got params %v, want 4 params", c.Params)
+		}
+	})
+
+	if maxParams := router.routes.Load().maxParams; maxParams != 4 {
+		t.Errorf("got maxParams %d, want 4", maxParams)
+	}
+	if w := performHostRequest(router, http.MethodGet, "a.b.example.com", "/1/2"); w.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", w.Code)
+	}
+	assertZeroAllocs(t, router, func() *http.Request {
+		req := newRequest(http.MethodGet, "/1/2")
+		req.Host = "a.b.example.com"
+		return req
+	}())
+}
+
+func TestHostInvalidPattern(t *testing.T) {
+	router := New()
+	for _, pattern := range []string{"", "a..example.com", ":.example.com", "example.com."} {
+		if catchPanic(func() { router.Host(pattern) }) == nil {
+			t.Errorf("Host(%q) did not panic", pattern)
+		}
+	}
+}
+
+func TestStripHostPort(t *testing.T) {
+	for host, want := range map[string]string{
+		"example.com":      "example.com",
+		"example.com:8080": "example.com",
+		"[::1]":            "[::1]",
+		"[::1]:8080":       "[::1]",
+		"":                 "",
+	} {
+		if got := stripHostPort(host); got != want {
+			t.Errorf("stripHostPort(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
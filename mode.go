@@ -0,0 +1,72 @@
+package gon
+
+import (
+	"flag"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// EnvGonMode 是用于设置 gon 运行模式的环境变量名
+const EnvGonMode = "GON_MODE"
+
+const (
+	// DebugMode 表示调试模式，会输出路由注册等调试信息
+	DebugMode = "debug"
+	// ReleaseMode 表示生产模式，不会输出任何调试信息
+	ReleaseMode = "release"
+	// TestMode 表示测试模式
+	TestMode = "test"
+)
+
+const (
+	debugCode = iota
+	releaseCode
+	testCode
+)
+
+// DefaultWriter 是 gon 默认用于输出调试信息的 io.Writer
+var DefaultWriter io.Writer = os.Stdout
+
+// DefaultErrorWriter 是 gon 默认用于输出错误信息的 io.Writer
+var DefaultErrorWriter io.Writer = os.Stderr
+
+var (
+	gonMode  int32        = debugCode // 当前的运行模式，使用原子操作读写
+	modeName atomic.Value             // 当前运行模式的名称
+)
+
+func init() {
+	mode := os.Getenv(EnvGonMode)
+	SetMode(mode)
+}
+
+// SetMode 根据传入的字符串设置 gon 的运行模式
+// 传入空字符串时，如果在 go test 中运行则使用 TestMode，否则使用 DebugMode
+func SetMode(value string) {
+	if value == "" {
+		if flag.Lookup("test.v") != nil {
+			value = TestMode
+		} else {
+			value = DebugMode
+		}
+	}
+
+	switch value {
+	case DebugMode:
+		atomic.StoreInt32(&gonMode, debugCode)
+	case ReleaseMode:
+		atomic.StoreInt32(&gonMode, releaseCode)
+	case TestMode:
+		atomic.StoreInt32(&gonMode, testCode)
+	default:
+		panic("gon mode unknown: " + value + " (available mode: debug release test)")
+	}
+
+	modeName.Store(value)
+}
+
+// Mode 返回当前 gon 的运行模式
+func Mode() string {
+	return modeName.Load().(string)
+}
@@ -0,0 +1,47 @@
+package gon
+
+import (
+	"os"
+	"testing"
+)
+
+func init() {
+	// 包初始化时 go test 的参数还没有注册，SetMode("") 无法判断是否在 go test 中运行，需要显式设置测试模式
+	SetMode(TestMode)
+}
+
+func TestSetMode(t *testing.T) {
+	defer SetMode(TestMode)
+
+	for _, tt := range []struct {
+		value     string
+		mode      string
+		debugging bool
+	}{
+		{DebugMode, DebugMode, true},
+		{ReleaseMode, ReleaseMode, false},
+		{TestMode, TestMode, false},
+		{"", TestMode, false}, // go test 中传入空字符串使用 TestMode
+	} {
+		SetMode(tt.value)
+		if Mode() != tt.mode || IsDebugging() != tt.debugging {
+			t.Errorf("SetMode(%q): got mode %q debugging %v, want %q %v", tt.value, Mode(), IsDebugging(), tt.mode, tt.debugging)
+		}
+	}
+
+	// init 使用 GON_MODE 环境变量设置运行模式
+	t.Setenv(EnvGonMode, ReleaseMode)
+	SetMode(os.Getenv(EnvGonMode))
+	if Mode() != ReleaseMode {
+		t.Errorf("SetMode with %s=%s: got mode %q", EnvGonMode, ReleaseMode, Mode())
+	}
+
+	SetMode(DebugMode)
+	err := catchPanic(func() { SetMode("unknown") })
+	if err == nil {
+		t.Error("SetMode with an unknown mode did not panic")
+	}
+	if Mode() != DebugMode {
+		t.Errorf("got mode %q after an unknown mode, want %q", Mode(), DebugMode)
+	}
+}
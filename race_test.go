@@ -0,0 +1,6 @@
+//go:build race
+
+package gon
+
+// raceEnabled 表示测试是否开启了竞态检测，竞态检测会引入额外的内存分配
+const raceEnabled = true
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JSON 将给定的数据序列化为 JSON 写入响应
+type JSON struct {
+	Data any
+}
+
+var jsonContentType = []string{"application/json; charset=utf-8"}
+
+// Render 实现了 Render 接口，将数据序列化为 JSON 写入响应
+func (r JSON) Render(w http.ResponseWriter) error {
+	return WriteJSON(w, r.Data)
+}
+
+// WriteContentType 实现了 Render 接口，写入 JSON 的 Content-Type
+func (r JSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// WriteJSON 将给定的对象序列化为 JSON 并写入响应
+func WriteJSON(w http.ResponseWriter, obj any) error {
+	writeContentType(w, jsonContentType)
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonBytes)
+	return err
+}
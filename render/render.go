@@ -0,0 +1,24 @@
+package render
+
+import "net/http"
+
+// Render 接口需要被 JSON 等各种响应渲染器实现
+type Render interface {
+	// Render 将数据写入到响应中，并设置对应的 Content-Type
+	Render(http.ResponseWriter) error
+	// WriteContentType 只写入 Content-Type 响应头
+	WriteContentType(w http.ResponseWriter)
+}
+
+// 确保各渲染器实现了 Render 接口
+var (
+	_ Render = JSON{}
+)
+
+// writeContentType 在响应头中没有设置 Content-Type 时，写入给定的 Content-Type
+func writeContentType(w http.ResponseWriter, value []string) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = value
+	}
+}
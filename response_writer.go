@@ -0,0 +1,161 @@
+package gon
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+const (
+	noWritten     = -1            // 表示响应还没有写入任何内容
+	defaultStatus = http.StatusOK // 默认的响应状态码
+)
+
+// ResponseWriter 扩展了 http.ResponseWriter，记录了响应的状态码和写入的字节数
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Hijacker
+	http.Flusher
+
+	// Status 返回当前请求的 HTTP 响应状态码
+	Status() int
+
+	// Size 返回已经写入响应体的字节数
+	Size() int
+
+	// WriteString 向响应体中写入字符串
+	WriteString(string) (int, error)
+
+	// Written 返回响应是否已经写入
+	Written() bool
+
+	// WriteHeaderNow 强制写入 HTTP 响应头
+	WriteHeaderNow()
+
+	// Pusher 返回用于 HTTP/2 服务端推送的 http.Pusher，不支持时返回 nil
+	Pusher() http.Pusher
+}
+
+// responseWriter 是 ResponseWriter 的默认实现，会被嵌入在 Context 中随 Context 一起复用
+type responseWriter struct {
+	http.ResponseWriter
+	size   int // 已经写入的字节数，noWritten 表示还没有写入响应头
+	status int // 响应状态码
+}
+
+// 确保 responseWriter 实现了 ResponseWriter 接口
+var _ ResponseWriter = (*responseWriter)(nil)
+
+// Unwrap 返回原始的 http.ResponseWriter，供 http.ResponseController 使用
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// reset 用于在 Context 复用时重置 responseWriter
+func (w *responseWriter) reset(writer http.ResponseWriter) {
+	w.ResponseWriter = writer
+	w.size = noWritten
+	w.status = defaultStatus
+}
+
+// WriteHeader 只记录状态码，真正的响应头会在第一次写入响应体或调用 WriteHeaderNow 时写入
+// 响应头已经写入后，不再允许修改状态码
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && w.status != code {
+		if w.Written() {
+			debugPrint("[WARNING] Headers were already written. Wanted to override status code %d with %d", w.status, code)
+			return
+		}
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 如果响应头还没有写入，则立即写入响应头
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = w.ResponseWriter.Write(data)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) WriteString(s string) (n int, err error) {
+	w.WriteHeaderNow()
+	n, err = io.WriteString(w.ResponseWriter, s)
+	w.size += n
+	return
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.size != noWritten
+}
+
+// Hijack 实现了 http.Hijacker 接口
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.size < 0 {
+		w.size = 0
+	}
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// Flush 实现了 http.Flusher 接口
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Pusher() (pusher http.Pusher) {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher
+	}
+	return nil
+}
+
+// headResponseWriter 用于 HEAD 请求使用 GET 路由处理的场景
+// 它会丢弃写入的响应体，只记录响应体的长度，并延迟写入响应头，以便在处理结束后设置 Content-Length
+type headResponseWriter struct {
+	http.ResponseWriter
+	status int // 处理函数写入的状态码，0 表示还没有写入
+	size   int // 被丢弃的响应体的长度
+}
+
+func (w *headResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *headResponseWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	return len(data), nil
+}
+
+// flush 在处理链执行完成后调用，补充 Content-Length 响应头并写入真正的响应头
+func (w *headResponseWriter) flush() {
+	if w.status == 0 {
+		w.status = defaultStatus
+	}
+	header := w.Header()
+	if header.Get("Content-Length") == "" && w.size > 0 && bodyAllowedForStatus(w.status) {
+		header.Set("Content-Length", strconv.Itoa(w.size))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
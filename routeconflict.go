@@ -0,0 +1,153 @@
+package gon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RouteConflictKind 表示路由冲突的类型
+type RouteConflictKind uint8
+
+const (
+	ConflictInvalidPath RouteConflictKind = iota + 1 // 路径本身不合法，例如通配符没有名称、约束不合法、catch-all 不在路径末尾等
+	ConflictDuplicate                                // 相同的路径已经注册了处理链
+	ConflictWildcard                                 // 通配符和已有路由中同一位置的通配符冲突，例如 "/users/:id" 和 "/users/:name"
+	ConflictCatchAll                                 // catch-all 通配符和已有路由中同一位置的路径段冲突
+)
+
+// String 返回冲突类型的名称
+func (k RouteConflictKind) String() string {
+	switch k {
+	case ConflictInvalidPath:
+		return "invalid path"
+	case ConflictDuplicate:
+		return "duplicate route"
+	case ConflictWildcard:
+		return "wildcard conflict"
+	case ConflictCatchAll:
+		return "catch-all conflict"
+	default:
+		return "unknown conflict"
+	}
+}
+
+// RouteConflictError 描述一个无法添加到路由树中的路由，注册路由时发生冲突会使用该类型的值触发 panic
+type RouteConflictError struct {
+	Kind         RouteConflictKind // 冲突类型
+	NewPath      string            // 新添加的路由路径
+	ExistingPath string            // 与新路由冲突的已有路由路径，路径本身不合法时为空字符串
+	Segment      string            // 新路由路径中发生冲突的路径段或通配符
+	msg          string            // 错误信息
+}
+
+// Error 实现了 error 接口
+func (e *RouteConflictError) Error() string {
+	return e.msg
+}
+
+// invalidPathError 返回一个表示路径本身不合法的 RouteConflictError
+func invalidPathError(path, segment, msg string) *RouteConflictError {
+	return &RouteConflictError{Kind: ConflictInvalidPath, NewPath: path, Segment: segment, msg: msg}
+}
+
+// RouteSpec 描述一个待注册的路由，用于 ValidateRoutes 检查路由冲突
+type RouteSpec struct {
+	Method string // 请求方式
+	Path   string // 路由的完整路径，支持参数约束和可选参数
+	Host   string // 路由所属的主机名模式，为空字符串时表示默认的路由树
+}
+
+// RouteConflict 表示 ValidateRoutes 发现的一个无法注册的路由
+type RouteConflict struct {
+	Route RouteSpec           // 无法注册的路由
+	Err   *RouteConflictError // 冲突的详细信息
+}
+
+// ValidateRoutes 依次检查 routes 中的路由能否注册到 Engine 中，返回所有无法注册的路由，全部可以注册时返回 nil
+// 检查在当前路由树的副本上进行，会同时检查和已注册路由以及 routes 中排在前面的路由之间的冲突，不会修改 Engine 的路由
+// 路由路径会和注册路由时一样使用 joinPaths 规范化，例如 "/./users" 会作为 "/users" 检查
+// 无法注册的路由不会影响后续路由的检查
+//
+//	conflicts := router.ValidateRoutes([]gon.RouteSpec{
+//	    {Method: http.MethodGet, Path: "/users/:id"},
+//	    {Method: http.MethodGet, Path: "/users/:name"}, // 和 "/users/:id" 冲突
+//	})
+func (engine *Engine) ValidateRoutes(routes []RouteSpec) []RouteConflict {
+	engine.routesMu.Lock()
+	defer engine.routesMu.Unlock()
+
+	table := engine.routes.Load()
+	// copyTree 返回 route 对应的已注册路由树的副本，路由树不存在时返回一个空的路由树
+	copyTree := func(route RouteSpec) *node {
+		if root := table.tree(route.Host, route.Method); root != nil {
+			return root.clone()
+		}
+		return &node{fullPath: "/"}
+	}
+
+	roots := make(map[string]*node)    // key 由 routeKey 生成，value 为检查过程中的路由树副本
+	added := make(map[string][]string) // 已经添加到路由树副本中的路径，key 和 roots 相同
+	var conflicts []RouteConflict
+	for _, route := range routes {
+		key := routeKey(strings.ToLower(route.Host), route.Method, "")
+		root, ok := roots[key]
+		if !ok {
+			root = copyTree(route)
+		}
+
+		// 每个路由树只复制一次，路由都添加到同一个副本中
+		// 添加失败时副本可能已经被修改了一部分，使用已注册的路由树和之前添加成功的路径重新构建副本
+		paths, err := engine.dryRunRoute(root, route)
+		if err != nil {
+			conflicts = append(conflicts, RouteConflict{Route: route, Err: err})
+			root = copyTree(route)
+			for _, p := range added[key] {
+				root.addRoute(p, HandlerChain{nil})
+			}
+		} else {
+			added[key] = append(added[key], paths...)
+		}
+		roots[key] = root
+	}
+	return conflicts
+}
+
+// tree 返回 host 主机名下 method 对应的路由树，不存在时返回 nil
+func (table *routeTable) tree(host, method string) *node {
+	if host == "" {
+		return table.trees.get(method)
+	}
+	for _, h := range table.hosts {
+		if strings.EqualFold(h.pattern, host) {
+			return h.trees.get(method)
+		}
+	}
+	return nil
+}
+
+// dryRunRoute 将路由添加到 root 中，返回添加的路径，并将添加过程中触发的 panic 转换为 RouteConflictError 返回
+// 路由树触发的 RouteConflictError 直接返回，其他 panic（例如 assert1 的检查失败或者运行时错误）都会作为路径不合法返回
+// ValidateRoutes 用于检查配置中的路由，任何无法注册的路由都应该被报告，而不是让进程崩溃
+func (engine *Engine) dryRunRoute(root *node, route RouteSpec) (paths []string, err *RouteConflictError) {
+	path := joinPaths("/", route.Path)
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case *RouteConflictError:
+			err = r
+		default:
+			err = invalidPathError(path, "", fmt.Sprint(r))
+		}
+	}()
+
+	assert1(route.Method != "" && regEnLetter.MatchString(route.Method), "http method "+route.Method+" is not valid")
+	if route.Host != "" {
+		newHostRoute(route.Host)
+	}
+	paths = expandOptionalPath(path)
+	for _, p := range paths {
+		engine.compileConstraints(p)
+		root.addRoute(p, HandlerChain{nil})
+	}
+	return paths, nil
+}
@@ -0,0 +1,118 @@
+package gon
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestValidateRoutes(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {})
+	router.Host("api.example.com").GET("/status", func(c *Context) {})
+
+	routes := []RouteSpec{
+		{Method: http.MethodGet, Path: "/users/:name"},                           // 0: 和已注册的路由冲突
+		{Method: http.MethodGet, Path: "/users/:id/posts"},                       // 和已注册的路由共享参数
+		{Method: http.MethodGet, Path: "/files/readme"},                          // 可以注册
+		{Method: http.MethodGet, Path: "/files/*path"},                           // 3: 和前面的静态路由冲突
+		{Method: http.MethodPost, Path: "/users/:name?"},                         // 其他请求方式的路由树，可选参数会展开为两个路由
+		{Method: http.MethodPost, Path: "/users/:name"},                          // 5: 和前面展开后的路由重复
+		{Method: http.MethodGet, Path: "/status", Host: "API.example.com"},       // 6: 主机名不区分大小写
+		{Method: http.MethodGet, Path: "/status", Host: "www.example.com"},       // 其他主机名
+		{Method: http.MethodGet, Path: "/./users/:name"},                         // 8: 规范化后和已注册的路由冲突
+		{Method: "get", Path: "/lower"},                                          // 9: 请求方式不合法
+		{Method: http.MethodGet, Path: "/x", Host: "a..example.com"},             // 10: 主机名不合法
+		{Method: http.MethodGet, Path: "/codes/:code<[a-z>"},                     // 11: 约束不是合法的正则表达式
+		{Method: http.MethodGet, Path: "/codes/:code<unknown_type>"},             // 12: 未注册的参数类型
+		{Method: http.MethodGet, Path: "/items/:id?/:name"},                      // 13: 可选参数后面存在必选参数
+		{Method: http.MethodGet, Path: "/items/:/x"},                             // 14: 通配符没有名称
+		{Method: http.MethodGet, Path: "/orders/:id<int>/:id<int>/x/*rest/tail"}, // 15: catch-all 不在末尾
+	}
+	want := map[int]RouteConflictKind{
+		0:  ConflictWildcard,
+		3:  ConflictCatchAll,
+		5:  ConflictDuplicate,
+		6:  ConflictDuplicate,
+		8:  ConflictWildcard,
+		9:  ConflictInvalidPath,
+		10: ConflictInvalidPath,
+		11: ConflictInvalidPath,
+		12: ConflictInvalidPath,
+		13: ConflictInvalidPath,
+		14: ConflictInvalidPath,
+		15: ConflictInvalidPath,
+	}
+
+	conflicts := router.ValidateRoutes(routes)
+	got := make(map[int]*RouteConflictError)
+	for _, conflict := range conflicts {
+		for i, route := range routes {
+			if route == conflict.Route {
+				got[i] = conflict.Err
+			}
+		}
+	}
+	for i, kind := range want {
+		if got[i] == nil || got[i].Kind != kind {
+			t.Errorf("route %d %+v: got %v, want %v", i, routes[i], got[i], kind)
+		}
+	}
+	if len(conflicts) != len(want) {
+		t.Errorf("got %d conflicts, want %d: %+v", len(conflicts), len(want), conflicts)
+	}
+
+	for _, i := range []int{0, 8} {
+		if conflict := got[i]; conflict == nil || conflict.NewPath != "/users/:name" || conflict.ExistingPath != "/users/:id" || conflict.Segment != ":name" {
+			t.Errorf("route %d: got %+v", i, conflict)
+		}
+	}
+
+	// 检查不会修改 Engine 的路由
+	if routes := router.Routes(); len(routes) != 2 {
+		t.Errorf("ValidateRoutes registered routes: %v", routes)
+	}
+	if conflicts := router.ValidateRoutes(routes[1:3]); conflicts != nil {
+		t.Errorf("got conflicts %+v for valid routes", conflicts)
+	}
+}
+
+func TestValidateRoutesCatchAllAtSegmentStart(t *testing.T) {
+	router := New()
+	routes := []RouteSpec{
+		{Method: http.MethodGet, Path: "/"},
+		{Method: http.MethodGet, Path: "/0"},
+		{Method: http.MethodGet, Path: "/0*0"}, // 通配符位于剩余路径的开头，前面没有 "/"
+	}
+	conflicts := router.ValidateRoutes(routes)
+	if len(conflicts) != 1 || conflicts[0].Route != routes[2] || conflicts[0].Err.Kind != ConflictInvalidPath {
+		t.Errorf("got %+v, want an invalid path conflict for %+v", conflicts, routes[2])
+	}
+}
+
+func TestValidateRoutesRebuildsAfterConflict(t *testing.T) {
+	router := New()
+	// "/a/:id?" 展开后的 "/a" 添加成功，"/a/:id" 添加失败，之后的路由需要在不包含 "/a" 的路由树上检查
+	routes := []RouteSpec{
+		{Method: http.MethodGet, Path: "/a/:name/x"},
+		{Method: http.MethodGet, Path: "/a/:id?"},
+		{Method: http.MethodGet, Path: "/a"},
+	}
+	conflicts := router.ValidateRoutes(routes)
+	if len(conflicts) != 1 || conflicts[0].Route != routes[1] || conflicts[0].Err.Kind != ConflictWildcard {
+		t.Errorf("got %+v, want a wildcard conflict for %+v", conflicts, routes[1])
+	}
+}
+
+func TestValidateRoutesReportsUnexpectedPanics(t *testing.T) {
+	router := New()
+	// 路由树为 nil 时添加路由会触发运行时错误，运行时错误同样作为路径不合法返回，不会让进程崩溃
+	var err *RouteConflictError
+	if recv := catchPanic(func() {
+		_, err = router.dryRunRoute(nil, RouteSpec{Method: http.MethodGet, Path: "/users"})
+	}); recv != nil {
+		t.Fatalf("dryRunRoute panicked: %v", recv)
+	}
+	if err == nil || err.Kind != ConflictInvalidPath || err.NewPath != "/users" {
+		t.Errorf("got %+v, want an invalid path conflict", err)
+	}
+}
@@ -2,7 +2,9 @@ package gon
 
 import (
 	"net/http"
+	"net/url"
 	"regexp"
+	"strings"
 )
 
 // regEnLetter 用于匹配请求方式是否都是大写字母，使用 regexp.MustCompile 编译一次后可以复用，以此提高性能
@@ -24,18 +26,21 @@ type IRouter interface {
 // IRoutes 定义了所有的路由处理函数的注册接口
 // 默认情况下，Engine 和 RouterGroup 都实现了 IRoutes 接口
 type IRoutes interface {
-	Use(...HandlerFunc) IRoutes // 注册路由中间件（处理函数链）
+	Use(...HandlerFunc) IRoutes                   // 注册路由中间件（处理函数链）
+	Prepend(...HandlerFunc) IRoutes               // 将中间件插入到处理链的最前面
+	UseAfter(HandlerFunc, ...HandlerFunc) IRoutes // 将中间件插入到处理链中指定中间件的后面
 
-	Handler(string, string, ...HandlerFunc) IRoutes // 传入请求方式和路径进行路由注册
+	Handler(string, string, ...HandlerFunc) *Route  // 传入请求方式和路径进行路由注册
 	Any(string, ...HandlerFunc) IRoutes             // 注册任意请求方式的路由处理函数
-	GET(string, ...HandlerFunc) IRoutes             // 注册 GET 请求的路由处理函数
-	POST(string, ...HandlerFunc) IRoutes            // 注册 POST 请求的路由处理函数
-	PUT(string, ...HandlerFunc) IRoutes             // 注册 PUT 请求的路由处理函数
-	DELETE(string, ...HandlerFunc) IRoutes          // 注册 DELETE 请求的路由处理函数
-	HEAD(string, ...HandlerFunc) IRoutes            // 注册 HEAD 请求的路由处理函数
-	PATCH(string, ...HandlerFunc) IRoutes           // 注册 PATCH 请求的路由处理函数
-	OPTIONS(string, ...HandlerFunc) IRoutes         // 注册 OPTIONS 请求的路由处理函数
+	GET(string, ...HandlerFunc) *Route              // 注册 GET 请求的路由处理函数
+	POST(string, ...HandlerFunc) *Route             // 注册 POST 请求的路由处理函数
+	PUT(string, ...HandlerFunc) *Route              // 注册 PUT 请求的路由处理函数
+	DELETE(string, ...HandlerFunc) *Route           // 注册 DELETE 请求的路由处理函数
+	HEAD(string, ...HandlerFunc) *Route             // 注册 HEAD 请求的路由处理函数
+	PATCH(string, ...HandlerFunc) *Route            // 注册 PATCH 请求的路由处理函数
+	OPTIONS(string, ...HandlerFunc) *Route          // 注册 OPTIONS 请求的路由处理函数
 	MATCH([]string, string, ...HandlerFunc) IRoutes // 注册多种请求方式的路由处理函数
+	Mount(string, http.Handler) IRoutes             // 将 http.Handler 挂载到指定前缀下，处理该前缀下的所有请求
 
 	// TODO 后续实现静态文件解析方法
 	// StaticFile(string, string) IRoutes                    // 注册静态文件路由
@@ -51,14 +56,42 @@ type RouterGroup struct {
 	basePath string       // 路由组的基础路径，根路由组的 basePath 是 "/"
 	engine   *Engine      // 指向引擎实例
 	root     bool         // 是否是根路由组
+	host     string       // 通过 Engine.Host 创建的路由组所属的主机名模式，为空字符串时路由注册到默认的路由树中
+}
+
+// Route 表示通过 GET、POST 等方法注册的一个路由，可以通过 Name 为该路由设置名称
+// Route 内嵌了注册该路由的路由组（根路由组时为 Engine），因此可以继续链式注册路由
+type Route struct {
+	IRoutes
+	engine *Engine // 指向引擎实例
+	method string  // 路由的请求方式
+	host   string  // 路由所属的主机名模式，为空字符串时表示默认的路由树
+	path   string  // 路由的完整路径，包含参数约束和可选参数
 }
 
 // 确保 RouterGroup 实现了 IRouter 接口，防止后期改错，如果不满足，编译器会报错
 var _ IRouter = (*RouterGroup)(nil)
 
 // Use 用于给路由组添加中间件（处理函数链）
+// 路由在注册时会拷贝一份路由组当前的处理链，因此 Use 只会影响之后注册的路由和创建的子路由组
 func (group *RouterGroup) Use(middlewares ...HandlerFunc) IRoutes {
-	group.Handlers = append(group.Handlers, middlewares...)
+	group.Handlers = group.combineHandlers(middlewares)
+	return group.returnObj()
+}
+
+// Prepend 用于将中间件插入到路由组处理链的最前面，例如需要在所有已注册的中间件之前执行的 Recovery 中间件
+func (group *RouterGroup) Prepend(middlewares ...HandlerFunc) IRoutes {
+	group.Handlers = group.insertHandlers(0, middlewares)
+	return group.returnObj()
+}
+
+// UseAfter 用于将中间件插入到路由组处理链中 target 中间件的后面
+// target 需要是通过 Use 等方法注册的同一个函数值，如果同一个函数值被注册了多次，则插入到第一个匹配的中间件后面
+// 如果 target 不在路由组的处理链中，则会触发 panic
+func (group *RouterGroup) UseAfter(target HandlerFunc, middlewares ...HandlerFunc) IRoutes {
+	pos := group.Handlers.indexOf(target)
+	assert1(pos >= 0, "middleware "+nameOfFunction(target)+" is not registered in the group")
+	group.Handlers = group.insertHandlers(pos+1, middlewares)
 	return group.returnObj()
 }
 
@@ -68,52 +101,57 @@ func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *R
 		Handlers: group.combineHandlers(handlers),
 		basePath: group.calculateAbsolutePath(relativePath),
 		engine:   group.engine,
+		host:     group.host,
 		root:     false, // 新创建的路由组不是根路由组
 	}
 
 }
 
 // handler 真实实现路由注册的逻辑，后续 GET、POST 等方法会调用该方法进行注册
-func (group *RouterGroup) handler(httpMethod, relativePath string, handlers HandlerChain) IRoutes{
+func (group *RouterGroup) handler(httpMethod, relativePath string, handlers HandlerChain) *Route {
 	absolutePath := group.calculateAbsolutePath(relativePath)
 	handlers = group.combineHandlers(handlers)
-	group.engine.addRoute(httpMethod, absolutePath, handlers)
-	return group.returnObj()
+	group.engine.addRoute(group.host, httpMethod, absolutePath, handlers)
+	group.engine.routesMu.Lock()
+	for _, p := range expandOptionalPath(absolutePath) {
+		group.engine.routeGroups[routeKey(group.host, httpMethod, p)] = group.basePath
+	}
+	group.engine.routesMu.Unlock()
+	return &Route{IRoutes: group.returnObj(), engine: group.engine, method: httpMethod, host: group.host, path: absolutePath}
 }
 
-
-func (group *RouterGroup) Handler(method string, path string, handlers ...HandlerFunc) IRoutes {
+func (group *RouterGroup) Handler(method string, path string, handlers ...HandlerFunc) *Route {
 	if matched := regEnLetter.MatchString(method); !matched {
 		panic("http method " + method + " is not valid")
 	}
 	return group.handler(method, path, handlers)
 }
 
-func (group *RouterGroup) GET(path string, handlers ...HandlerFunc) IRoutes {
+func (group *RouterGroup) GET(path string, handlers ...HandlerFunc) *Route {
 	return group.handler(http.MethodGet, path, handlers)
 }
 
-func (group *RouterGroup) POST(path string, handlers ...HandlerFunc) IRoutes {
+func (group *RouterGroup) POST(path string, handlers ...HandlerFunc) *Route {
 	return group.handler(http.MethodPost, path, handlers)
 }
 
-func (group *RouterGroup) PUT(path string, handlers ...HandlerFunc) IRoutes {
+func (group *RouterGroup) PUT(path string, handlers ...HandlerFunc) *Route {
 	return group.handler(http.MethodPut, path, handlers)
 }
 
-func (group *RouterGroup) DELETE(path string, handlers ...HandlerFunc) IRoutes {
+func (group *RouterGroup) DELETE(path string, handlers ...HandlerFunc) *Route {
 	return group.handler(http.MethodDelete, path, handlers)
 }
 
-func (group *RouterGroup) HEAD(path string, handlers ...HandlerFunc) IRoutes {
+func (group *RouterGroup) HEAD(path string, handlers ...HandlerFunc) *Route {
 	return group.handler(http.MethodHead, path, handlers)
 }
 
-func (group *RouterGroup) PATCH(path string, handlers ...HandlerFunc) IRoutes {
+func (group *RouterGroup) PATCH(path string, handlers ...HandlerFunc) *Route {
 	return group.handler(http.MethodPatch, path, handlers)
 }
 
-func (group *RouterGroup) OPTIONS(path string, handlers ...HandlerFunc) IRoutes {
+func (group *RouterGroup) OPTIONS(path string, handlers ...HandlerFunc) *Route {
 	return group.handler(http.MethodOptions, path, handlers)
 }
 
@@ -134,15 +172,105 @@ func (group *RouterGroup) MATCH(methods []string, path string, handlers ...Handl
 	return group.returnObj()
 }
 
+// ReplaceRoute 使用新的处理链替换请求方式为 method、路径为 relativePath 的已注册路由，路由不存在时返回 false
+// 新的处理链同样会合并当前路由组的处理链，路由可以在处理请求的同时替换，正在处理的请求会继续使用旧的处理链
+func (group *RouterGroup) ReplaceRoute(method, relativePath string, handlers ...HandlerFunc) bool {
+	assert1(len(handlers) > 0, "there must be at least one handler")
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	if !group.engine.replaceRoute(group.host, method, absolutePath, group.combineHandlers(handlers)) {
+		return false
+	}
+
+	group.engine.routesMu.Lock()
+	for _, p := range expandOptionalPath(absolutePath) {
+		group.engine.routeGroups[routeKey(group.host, method, p)] = group.basePath
+	}
+	group.engine.routesMu.Unlock()
+	return true
+}
+
+// RemoveRoute 删除请求方式为 method、路径为 relativePath 的已注册路由，路由不存在时返回 false
+// 路由可以在处理请求的同时删除，正在处理的请求不受影响
+func (group *RouterGroup) RemoveRoute(method, relativePath string) bool {
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	if !group.engine.removeRoute(group.host, method, absolutePath) {
+		return false
+	}
+
+	group.engine.routesMu.Lock()
+	for _, p := range expandOptionalPath(absolutePath) {
+		delete(group.engine.routeGroups, routeKey(group.host, method, p))
+	}
+	group.engine.removeNamedRoutes(group.host, method, expandOptionalPath(absolutePath))
+	group.engine.routesMu.Unlock()
+	return true
+}
+
+// Mount 将 http.Handler 挂载到 prefix 下，prefix 及其下所有路径的任意请求方式的请求都会交给 handler 处理
+// handler 收到的请求中 URL.Path 和 URL.RawPath 会去除 prefix，去除后的路径总是以 "/" 开头
+// 路由组的处理链会在 handler 之前执行，挂载另一个 *Engine 时，请求会先经过当前路由组的中间件，再经过子 Engine 自身的中间件
+//
+//	admin := gon.New()
+//	admin.GET("/users", listUsers)
+//	router.Group("/v1", auth).Mount("/admin", admin) // GET /v1/admin/users 经过 auth 后由 admin 处理
+func (group *RouterGroup) Mount(prefix string, handler http.Handler) IRoutes {
+	absolutePrefix := strings.TrimSuffix(group.calculateAbsolutePath(prefix), "/")
+	serve := func(c *Context) {
+		handler.ServeHTTP(c.Writer, stripPrefix(c.Request, absolutePrefix))
+	}
+
+	// 同时注册 prefix 和 prefix 下的通配符路由，prefix 为 "/" 时只需要注册通配符路由
+	relativePrefix := strings.TrimSuffix(prefix, "/")
+	paths := []string{relativePrefix + "/*mountpath"}
+	if absolutePrefix != "" {
+		paths = append(paths, relativePrefix)
+	}
+	for _, method := range anyMethods {
+		for _, p := range paths {
+			group.handler(method, p, HandlerChain{serve})
+		}
+	}
+	return group.returnObj()
+}
+
+// stripPrefix 返回去除了路径前缀 prefix 的请求的浅拷贝，原请求不会被修改
+func stripPrefix(req *http.Request, prefix string) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.URL = new(url.URL)
+	*r.URL = *req.URL
+	r.URL.Path = trimPathPrefix(req.URL.Path, prefix)
+	if req.URL.RawPath != "" {
+		r.URL.RawPath = trimPathPrefix(req.URL.RawPath, prefix)
+	}
+	return r
+}
+
+// trimPathPrefix 去除路径的前缀 prefix，并确保返回的路径以 "/" 开头
+func trimPathPrefix(path, prefix string) string {
+	path = strings.TrimPrefix(path, prefix)
+	if path == "" || path[0] != '/' {
+		return "/" + path
+	}
+	return path
+}
+
 // combineHandlers 用于合并当前路由组的处理链和传入的处理函数链，使用深拷贝返回一个新的函数处理链
 func (group *RouterGroup) combineHandlers(handlers HandlerChain) HandlerChain {
+	return group.insertHandlers(len(group.Handlers), handlers)
+}
+
+// insertHandlers 用于将传入的处理函数链插入到当前路由组处理链的 pos 位置，使用深拷贝返回一个新的函数处理链
+// 返回的处理链和路由组的处理链不共享底层数组，后续对路由组的修改不会影响已经返回的处理链
+func (group *RouterGroup) insertHandlers(pos int, handlers HandlerChain) HandlerChain {
 	finalSize := len(group.Handlers) + len(handlers)
 	assert1(finalSize < int(abortIndex), "too many handlers")
-	mergedHandlers := make(HandlerChain, 0, finalSize)
+	mergedHandlers := make(HandlerChain, finalSize)
 
 	// 深拷贝当前路由组的处理链和传入的处理函数链
-	copy(mergedHandlers, group.Handlers)
-	copy(mergedHandlers[len(group.Handlers):], handlers)
+	copy(mergedHandlers, group.Handlers[:pos])
+	copy(mergedHandlers[pos:], handlers)
+	copy(mergedHandlers[pos+len(handlers):], group.Handlers[pos:])
 	return mergedHandlers
 }
 
@@ -0,0 +1,314 @@
+package gon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMount(t *testing.T) {
+	var gotPath, gotRawPath, originalPath string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotRawPath = r.URL.Path, r.URL.RawPath
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	router := New()
+	router.UseRawPath = true
+	router.Use(func(c *Context) {
+		c.Next()
+		originalPath = c.Request.URL.Path
+	})
+	router.Mount("/static/", handler)
+
+	for _, tt := range []struct {
+		method, target string
+		path, rawPath  string
+		code           int
+	}{
+		{http.MethodGet, "/static/css/app.css", "/css/app.css", "", http.StatusTeapot},
+		{http.MethodPost, "/static", "/", "", http.StatusTeapot},
+		{http.MethodDelete, "/static/", "/", "", http.StatusTeapot},
+		{http.MethodGet, "/static/a%2Fb", "/a/b", "/a%2Fb", http.StatusTeapot},
+	} {
+		gotPath, gotRawPath, originalPath = "", "", ""
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code || gotPath != tt.path || gotRawPath != tt.rawPath {
+			t.Errorf("%s %s: got %d %q %q, want %d %q %q", tt.method, tt.target, w.Code, gotPath, gotRawPath, tt.code, tt.path, tt.rawPath)
+		}
+		if originalPath != req.URL.Path {
+			t.Errorf("%s %s: Mount modified the original request path to %q", tt.method, tt.target, originalPath)
+		}
+	}
+
+	if w := performRequest(router, http.MethodGet, "/staticfile"); w.Code != http.StatusNotFound {
+		t.Errorf("GET /staticfile: got %d, want 404", w.Code)
+	}
+}
+
+func TestMountRoot(t *testing.T) {
+	var gotPath string
+	router := New()
+	router.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+
+	for _, path := range []string{"/", "/a/b"} {
+		if w := performRequest(router, http.MethodGet, path); w.Code != http.StatusOK || gotPath != path {
+			t.Errorf("GET %s: got %d %q", path, w.Code, gotPath)
+		}
+	}
+}
+
+func TestMountEngine(t *testing.T) {
+	trace := func(name string) HandlerFunc {
+		return func(c *Context) {
+			c.Writer.Header().Add("X-Trace", name)
+		}
+	}
+
+	admin := New()
+	admin.Use(trace("admin"))
+	admin.GET("/users/:id", func(c *Context) {
+		c.Writer.Header().Add("X-Trace", "user:"+c.Param("id")+":"+c.FullPath())
+	})
+
+	router := New()
+	router.Use(trace("global"))
+	router.Group("/v1", trace("v1")).Mount("/admin", admin)
+
+	w := performRequest(router, http.MethodGet, "/v1/admin/users/42")
+	want := "global,v1,admin,user:42:/users/:id"
+	if got := strings.Join(w.Header().Values("X-Trace"), ","); w.Code != http.StatusOK || got != want {
+		t.Errorf("GET /v1/admin/users/42: got %d %q, want 200 %q", w.Code, got, want)
+	}
+
+	// 子 Engine 中不存在的路由由子 Engine 返回 404，父路由组的中间件仍然会执行
+	w = performRequest(router, http.MethodGet, "/v1/admin/missing")
+	if got := strings.Join(w.Header().Values("X-Trace"), ","); w.Code != http.StatusNotFound || got != "global,v1,admin" {
+		t.Errorf("GET /v1/admin/missing: got %d %q", w.Code, got)
+	}
+}
+
+// traceMiddleware 返回一个在 X-Trace 响应头中记录 name 的中间件，用于检查处理链的执行顺序
+func traceMiddleware(name string) HandlerFunc {
+	return func(c *Context) {
+		c.Writer.Header().Add("X-Trace", name)
+	}
+}
+
+func TestMiddlewareComposition(t *testing.T) {
+	a, b, c, d := traceMiddleware("a"), traceMiddleware("b"), traceMiddleware("c"), traceMiddleware("d")
+	handler := traceMiddleware("handler")
+
+	for _, tt := range []struct {
+		name  string
+		setup func(router *Engine)
+		path  string
+		want  string
+	}{
+		{
+			name: "engine use",
+			setup: func(router *Engine) {
+				router.Use(a, b)
+				router.GET("/", handler)
+			},
+			path: "/",
+			want: "a,b,handler",
+		},
+		{
+			name: "nested groups",
+			setup: func(router *Engine) {
+				router.Use(a)
+				v1 := router.Group("/v1", b)
+				v1.Group("/users", c).GET("/:id", d, handler)
+				v1.GET("/status", handler)
+			},
+			path: "/v1/users/1",
+			want: "a,b,c,d,handler",
+		},
+		{
+			name: "sibling groups do not share handlers",
+			setup: func(router *Engine) {
+				v1 := router.Group("/v1", a)
+				v1.Group("/x", b).GET("/", handler)
+				v1.Group("/y", c).GET("/", handler)
+			},
+			path: "/v1/y/",
+			want: "a,c,handler",
+		},
+		{
+			name: "use after registration does not change the route",
+			setup: func(router *Engine) {
+				router.Use(a)
+				router.GET("/", handler)
+				router.Use(b)
+			},
+			path: "/",
+			want: "a,handler",
+		},
+		{
+			name: "use after creating a group does not change the group",
+			setup: func(router *Engine) {
+				api := router.Group("/api", a)
+				router.Use(b)
+				api.GET("/", handler)
+			},
+			path: "/api/",
+			want: "a,handler",
+		},
+		{
+			name: "group use after registration",
+			setup: func(router *Engine) {
+				api := router.Group("/api", a)
+				api.GET("/old", handler)
+				api.Use(b)
+				api.GET("/new", handler)
+			},
+			path: "/api/old",
+			want: "a,handler",
+		},
+		{
+			name: "prepend",
+			setup: func(router *Engine) {
+				router.Use(a, b)
+				router.Prepend(c, d)
+				router.GET("/", handler)
+			},
+			path: "/",
+			want: "c,d,a,b,handler",
+		},
+		{
+			name: "prepend in group",
+			setup: func(router *Engine) {
+				router.Use(a)
+				router.Group("/api", b).Prepend(c).GET("/", handler)
+			},
+			path: "/api/",
+			want: "c,a,b,handler",
+		},
+		{
+			name: "use after",
+			setup: func(router *Engine) {
+				router.Use(a, b)
+				router.UseAfter(a, c, d)
+				router.GET("/", handler)
+			},
+			path: "/",
+			want: "a,c,d,b,handler",
+		},
+		{
+			name: "use after last",
+			setup: func(router *Engine) {
+				router.Use(a, b)
+				router.UseAfter(b, c)
+				router.GET("/", handler)
+			},
+			path: "/",
+			want: "a,b,c,handler",
+		},
+		{
+			name: "use after inherited middleware",
+			setup: func(router *Engine) {
+				router.Use(a)
+				router.Group("/api", b).UseAfter(a, c).GET("/", handler)
+			},
+			path: "/api/",
+			want: "a,c,b,handler",
+		},
+		{
+			name: "use after duplicate target",
+			setup: func(router *Engine) {
+				router.Use(a, b, a)
+				router.UseAfter(a, c)
+				router.GET("/", handler)
+			},
+			path: "/",
+			want: "a,c,b,a,handler",
+		},
+		{
+			name: "global middleware applies to 404",
+			setup: func(router *Engine) {
+				router.Use(a)
+				router.Prepend(b)
+				router.UseAfter(a, c)
+			},
+			path: "/missing",
+			want: "b,a,c",
+		},
+	} {
+		router := New()
+		tt.setup(router)
+		w := performRequest(router, http.MethodGet, tt.path)
+		if got := strings.Join(w.Header().Values("X-Trace"), ","); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMiddlewareCompositionSnapshot(t *testing.T) {
+	router := New()
+	api := router.Group("/api", traceMiddleware("a"))
+	api.GET("/", traceMiddleware("handler"))
+
+	// 修改路由组的处理链不会影响已经注册的路由使用的处理链
+	api.Handlers[0] = traceMiddleware("changed")
+	w := performRequest(router, http.MethodGet, "/api/")
+	if got := strings.Join(w.Header().Values("X-Trace"), ","); got != "a,handler" {
+		t.Errorf("got %q, want a,handler", got)
+	}
+}
+
+func TestEngineUseOnce(t *testing.T) {
+	router := New()
+	router.Use(traceMiddleware("a"))
+	router.Prepend(traceMiddleware("b"))
+	router.UseAfter(router.Handlers[0], traceMiddleware("c"))
+
+	if len(router.Handlers) != 3 {
+		t.Errorf("got %d global handlers, want 3", len(router.Handlers))
+	}
+	router.GET("/", traceMiddleware("handler"))
+	w := performRequest(router, http.MethodGet, "/")
+	if got := strings.Join(w.Header().Values("X-Trace"), ","); got != "b,c,a,handler" {
+		t.Errorf("got %q, want b,c,a,handler", got)
+	}
+
+	// 404 和 405 处理链只包含全局中间件，OPTIONS 处理链还包含自动响应的处理函数
+	for name, tt := range map[string]struct {
+		chain HandlerChain
+		want  int
+	}{
+		"404":     {router.allNoRoute, 3},
+		"405":     {router.allNoMethod, 3},
+		"OPTIONS": {router.allOptions, 4},
+	} {
+		if len(tt.chain) != tt.want {
+			t.Errorf("%s chain has %d handlers, want %d", name, len(tt.chain), tt.want)
+		}
+	}
+}
+
+func TestUseAfterUnknownTarget(t *testing.T) {
+	router := New()
+	router.Use(traceMiddleware("a"))
+	for name, group := range map[string]IRoutes{"engine": router, "group": router.Group("/api")} {
+		if catchPanic(func() { group.UseAfter(traceMiddleware("a"), traceMiddleware("b")) }) == nil {
+			t.Errorf("%s: UseAfter with an unknown target did not panic", name)
+		}
+	}
+}
+
+func TestTooManyHandlers(t *testing.T) {
+	router := New()
+	handlers := make(HandlerChain, abortIndex)
+	for i := range handlers {
+		handlers[i] = traceMiddleware("h")
+	}
+	if catchPanic(func() { router.GET("/", handlers...) }) == nil {
+		t.Error("registering too many handlers did not panic")
+	}
+}
@@ -0,0 +1,147 @@
+package gon
+
+import "slices"
+
+// routeTable 是 Engine 中所有路由树的一个快照
+// 快照一旦发布就不会再被修改，修改路由时会复制需要修改的路由树，修改完成后原子地替换整个快照
+// 因此正在处理的请求总是使用一个完整的快照查找路由，不会看到修改了一半的路由树
+type routeTable struct {
+	trees       methodTrees  // 默认的路由树
+	hosts       []*hostRoute // 通过 Host 注册的主机名及其路由树，不包含参数的主机名排在前面
+	maxParams   uint16       // 所有路由中参数最多的路由的参数个数（包括主机名中的参数），用于分配 Context 的 Params 数组长度
+	maxSections uint16       // 所有路由中分段最多的路由的分段数量，路径分段是指路径中以 "/" 分割的部分
+}
+
+// updateTree 由 update 修改 host 主机名下 method 对应的路由树后发布一个新的路由表快照，host 为空字符串时修改默认的路由树
+// update 接收当前的路由树（路由树不存在时为 nil），返回新的路由树以及路由树是否被修改，返回的路由树为 nil 时会删除该请求方式的路由树
+// Engine 开始处理请求之后，当前的路由树可能正在被其他请求使用，update 需要通过 mutableTree 获取可以修改的路由树
+// limits 在新的路由树设置到路由表之后调用，用于更新路由表的 maxParams 和 maxSections，hostParams 为主机名中参数的数量，为 nil 时不更新
+// 所有修改路由的操作都是串行执行的，update 触发 panic 时不会发布新的快照
+func (engine *Engine) updateTree(host, method string, update func(root *node) (*node, bool), limits func(table *routeTable, hostParams uint16)) bool {
+	engine.routesMu.Lock()
+	defer engine.routesMu.Unlock()
+
+	table := *engine.routes.Load()
+	trees, hostParams := &table.trees, uint16(0)
+	if host != "" {
+		var h *hostRoute
+		table.hosts, h = withHost(table.hosts, host)
+		trees, hostParams = &h.trees, h.params
+	}
+
+	root, changed := update(trees.get(method))
+	if !changed {
+		return false
+	}
+	*trees = trees.with(method, root)
+	if limits != nil {
+		limits(&table, hostParams)
+	}
+	engine.routes.Store(&table)
+	return true
+}
+
+// mutableTree 返回可以直接修改的路由树，必须在持有 routesMu 时调用
+// Engine 开始处理请求之前路由树只会被注册路由的代码访问，直接在原路由树上修改，避免注册每个路由时都复制整个路由树
+// 开始处理请求之后返回路由树的副本，正在处理的请求仍然使用原路由树
+func (engine *Engine) mutableTree(root *node) *node {
+	if engine.serving.Load() {
+		return root.clone()
+	}
+	return root
+}
+
+// startServing 将 Engine 标记为已经开始处理请求，之后所有修改路由的操作都会复制路由树
+// 需要等待正在进行的修改完成，否则正在处理的请求可能会看到修改了一半的路由树
+func (engine *Engine) startServing() {
+	engine.routesMu.Lock()
+	engine.serving.Store(true)
+	engine.routesMu.Unlock()
+}
+
+// addLimits 使用新添加的路由更新 maxParams 和 maxSections，添加路由时两者只会变大
+func (table *routeTable) addLimits(paths []string, hostParams uint16) {
+	for _, p := range paths {
+		table.maxParams = max(table.maxParams, countParams(p)+hostParams)
+		table.maxSections = max(table.maxSections, countSections(p))
+	}
+}
+
+// updateLimits 遍历所有的路由，重新计算 maxParams 和 maxSections，删除路由后两者都可能变小
+func (table *routeTable) updateLimits() {
+	table.maxParams, table.maxSections = 0, 0
+	update := func(trees methodTrees, hostParams uint16) {
+		for _, tree := range trees {
+			for _, route := range tree.root.routes(nil) {
+				table.maxParams = max(table.maxParams, countParams(route.fullPath)+hostParams)
+				table.maxSections = max(table.maxSections, countSections(route.fullPath))
+			}
+		}
+	}
+	update(table.trees, 0)
+	for _, h := range table.hosts {
+		update(h.trees, h.params)
+	}
+}
+
+// removeRoute 删除 host 主机名下请求方式为 method、路径为 path 的路由，路由不存在时返回 false
+// 路径中包含可选参数时会删除所有展开后的路由
+func (engine *Engine) removeRoute(host, method, path string) bool {
+	paths := expandOptionalPath(path)
+	return engine.updateTree(host, method, func(root *node) (*node, bool) {
+		if root == nil {
+			return nil, false
+		}
+
+		routes := root.routes(nil)
+		kept := slices.DeleteFunc(slices.Clone(routes), func(route *node) bool {
+			return slices.Contains(paths, route.fullPath)
+		})
+		if len(kept) == len(routes) {
+			return nil, false
+		}
+		if len(kept) == 0 {
+			return nil, true
+		}
+
+		// 路由树中删除节点需要合并节点并重新计算优先级，直接使用剩余的路由重新构建路由树
+		newRoot := &node{fullPath: "/"}
+		for _, route := range kept {
+			newRoot.addRoute(route.fullPath, route.handlers)
+			newRoot.copyConstraints(root, route.fullPath)
+		}
+		return newRoot, true
+	}, func(table *routeTable, _ uint16) {
+		table.updateLimits()
+	})
+}
+
+// replaceRoute 替换 host 主机名下请求方式为 method、路径为 path 的路由的处理链，路由不存在时返回 false
+// 路径中包含可选参数时会替换所有展开后的路由，只要有一个展开后的路由不存在就不会进行替换
+func (engine *Engine) replaceRoute(host, method, path string, handlers HandlerChain) bool {
+	paths := expandOptionalPath(path)
+	replaced := engine.updateTree(host, method, func(root *node) (*node, bool) {
+		if root == nil {
+			return nil, false
+		}
+
+		// 先检查所有展开后的路由都存在，再修改处理链，避免直接修改原路由树时只替换了一部分路由
+		for _, p := range paths {
+			if root.findRoute(p) == nil {
+				return nil, false
+			}
+		}
+		newRoot := engine.mutableTree(root)
+		for _, p := range paths {
+			newRoot.findRoute(p).handlers = handlers
+		}
+		return newRoot, true
+	}, nil)
+
+	if replaced {
+		for _, p := range paths {
+			debugPrintRoute(method, p, handlers)
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,215 @@
+package gon
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRouteTableCopyOnWrite(t *testing.T) {
+	router := New()
+	router.GET("/a", func(c *Context) {})
+	root := router.routes.Load().trees.get(http.MethodGet)
+
+	// 开始处理请求之前直接在原路由树上添加路由
+	router.GET("/b", func(c *Context) {})
+	if router.routes.Load().trees.get(http.MethodGet) != root {
+		t.Error("routes registered before serving copied the tree")
+	}
+
+	performRequest(router, http.MethodGet, "/a")
+
+	// 开始处理请求之后添加路由会复制路由树，旧的快照不受影响
+	old := router.routes.Load()
+	router.GET("/c", func(c *Context) {})
+	if router.routes.Load().trees.get(http.MethodGet) == root {
+		t.Error("routes registered while serving modified the published tree")
+	}
+	if value := old.trees.get(http.MethodGet).getValue("/c", nil, getSkippedNodes(), false); value.handlers != nil {
+		t.Error("old snapshot sees the new route")
+	}
+	if w := performRequest(router, http.MethodGet, "/c"); w.Code != http.StatusOK {
+		t.Errorf("GET /c: got %d, want 200", w.Code)
+	}
+}
+
+func TestRouteTableLimits(t *testing.T) {
+	router := New()
+	router.GET("/a/:b/:c", func(c *Context) {})
+	router.GET("/a/b/c/d/e", func(c *Context) {})
+	router.Host(":tenant.example.com").GET("/:id", func(c *Context) {})
+
+	table := router.routes.Load()
+	if table.maxParams != 2 || table.maxSections != 5 {
+		t.Errorf("got maxParams %d maxSections %d, want 2 5", table.maxParams, table.maxSections)
+	}
+
+	router.RemoveRoute(http.MethodGet, "/a/b/c/d/e")
+	router.RemoveRoute(http.MethodGet, "/a/:b/:c")
+	table = router.routes.Load()
+	if table.maxParams != 2 || table.maxSections != 1 {
+		t.Errorf("after remove: got maxParams %d maxSections %d, want 2 1", table.maxParams, table.maxSections)
+	}
+}
+
+func TestDebugPrintRouteFuncCanReadRoutes(t *testing.T) {
+	router := New()
+	var count int
+	DebugPrintRouteFunc = func(httpMethod, absolutePath, handlerName string, nuHandlers int) {
+		count = len(router.Routes()) // 调试信息在释放 routesMu 之后输出，这里不会死锁
+	}
+	SetMode(DebugMode)
+	defer func() {
+		SetMode(TestMode)
+		DebugPrintRouteFunc = nil
+	}()
+
+	router.GET("/a", func(c *Context) {})
+	router.ReplaceRoute(http.MethodGet, "/a", func(c *Context) {})
+	if count != 1 {
+		t.Errorf("got %d routes in DebugPrintRouteFunc, want 1", count)
+	}
+}
+
+func TestRemoveRouteNamedRoutes(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {}).Name("user")
+	router.POST("/users/:id", func(c *Context) {}).Name("user.update")
+	router.Host("api.example.com").GET("/users/:id", func(c *Context) {}).Name("api.user")
+	router.GET("/posts/:id", func(c *Context) {}).Name("post")
+	router.GET("/items/:id?", func(c *Context) {}).Name("items")
+
+	// 只删除相同主机名和请求方式的路由的名称
+	router.RemoveRoute(http.MethodGet, "/users/:id")
+	for name, want := range map[string]bool{"user": false, "user.update": true, "api.user": true} {
+		if _, err := router.URL(name, Param{Key: "id", Value: "1"}); (err == nil) != want {
+			t.Errorf("URL(%s) after removing GET /users/:id: %v", name, err)
+		}
+	}
+
+	router.Host("api.example.com").RemoveRoute(http.MethodGet, "/users/:id")
+	if _, err := router.URL("api.user", Param{Key: "id", Value: "1"}); err == nil {
+		t.Error("URL(api.user) still works after the host route was removed")
+	}
+	if url, err := router.URL("post", Param{Key: "id", Value: "1"}); err != nil || url != "/posts/1" {
+		t.Errorf("URL(post) = %q, %v", url, err)
+	}
+
+	// 只删除可选参数展开后的一个路径时同样删除名称
+	router.RemoveRoute(http.MethodGet, "/items/:id")
+	if _, err := router.URL("items"); err == nil {
+		t.Error("URL(items) still works after part of the route was removed")
+	}
+
+	// 删除后名称可以重新使用
+	router.GET("/members/:id", func(c *Context) {}).Name("user")
+}
+
+func TestReplaceRoute(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) { c.Status(http.StatusOK) })
+	api := router.Group("/api", func(c *Context) { c.Writer.Header().Set("X-Group", "api") })
+	api.GET("/items/:id?", func(c *Context) { c.Status(http.StatusOK) })
+
+	if !router.ReplaceRoute(http.MethodGet, "/users/:id", func(c *Context) { c.Status(http.StatusAccepted) }) {
+		t.Fatal("ReplaceRoute(/users/:id) returned false")
+	}
+	if w := performRequest(router, http.MethodGet, "/users/1"); w.Code != http.StatusAccepted {
+		t.Errorf("GET /users/1: got %d, want 202", w.Code)
+	}
+
+	// 新的处理链会合并路由组的处理链，可选参数展开后的路由都会被替换
+	if !api.ReplaceRoute(http.MethodGet, "/items/:id?", func(c *Context) { c.Status(http.StatusCreated) }) {
+		t.Fatal("ReplaceRoute(/api/items/:id?) returned false")
+	}
+	for _, path := range []string{"/api/items", "/api/items/1"} {
+		if w := performRequest(router, http.MethodGet, path); w.Code != http.StatusCreated || w.Header().Get("X-Group") != "api" {
+			t.Errorf("GET %s: got %d %q, want 201 api", path, w.Code, w.Header().Get("X-Group"))
+		}
+	}
+
+	for _, tt := range []struct{ method, path string }{
+		{http.MethodGet, "/users"},
+		{http.MethodPost, "/users/:id"},
+		{http.MethodGet, "/users/:name"},
+	} {
+		if router.ReplaceRoute(tt.method, tt.path, func(c *Context) {}) {
+			t.Errorf("ReplaceRoute(%s %s) of a missing route returned true", tt.method, tt.path)
+		}
+	}
+}
+
+func TestRemoveRoute(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {})
+	router.GET("/users/:id/posts", func(c *Context) {})
+	router.GET("/items/:id?", func(c *Context) {})
+	router.POST("/users/:id", func(c *Context) {})
+
+	if !router.RemoveRoute(http.MethodGet, "/users/:id") {
+		t.Fatal("RemoveRoute(GET /users/:id) returned false")
+	}
+	if router.RemoveRoute(http.MethodGet, "/users/:id") {
+		t.Error("removing a route twice returned true")
+	}
+	for _, tt := range []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/users/1", http.StatusNotFound},
+		{http.MethodGet, "/users/1/posts", http.StatusOK},
+		{http.MethodPost, "/users/1", http.StatusOK},
+	} {
+		if w := performRequest(router, tt.method, tt.path); w.Code != tt.code {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.path, w.Code, tt.code)
+		}
+	}
+
+	// 可选参数展开后的路由会被全部删除，请求方式的最后一个路由被删除后路由树也会被删除
+	router.RemoveRoute(http.MethodGet, "/users/:id/posts")
+	router.RemoveRoute(http.MethodGet, "/items/:id?")
+	if root := router.routes.Load().trees.get(http.MethodGet); root != nil {
+		t.Error("GET tree still exists after all GET routes were removed")
+	}
+	if len(router.Routes()) != 1 {
+		t.Errorf("got routes %v, want only POST /users/:id", router.Routes())
+	}
+}
+
+// TestRoutesChangeWhileServing 在处理请求的同时添加、替换和删除路由，需要使用 -race 运行才能发现数据竞争
+func TestRoutesChangeWhileServing(t *testing.T) {
+	router := New()
+	router.GET("/static", func(c *Context) {})
+	router.GET("/users/:id", func(c *Context) {})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if w := performRequest(router, http.MethodGet, "/static"); w.Code != http.StatusOK {
+					t.Errorf("GET /static: got %d, want 200", w.Code)
+					return
+				}
+				performRequest(router, http.MethodGet, "/dynamic/1/a/b")
+			}
+		}()
+	}
+
+	for i := range 200 {
+		path := "/dynamic/:id/" + strconv.Itoa(i) + "/:a/:b"
+		router.GET(path, func(c *Context) {})
+		router.ReplaceRoute(http.MethodGet, "/users/:id", func(c *Context) {})
+		router.RemoveRoute(http.MethodGet, path)
+	}
+	close(stop)
+	wg.Wait()
+}
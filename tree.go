@@ -2,7 +2,11 @@ package gon
 
 import (
 	"bytes"
+	"net/url"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/stolenzc/gon/internal/bytesconv"
 )
@@ -24,6 +28,22 @@ type Param struct {
 // 因此，通过索引读取值是安全的。
 type Params []Param
 
+// Get 返回第一个键名匹配的参数值，以及是否找到了该参数
+func (ps Params) Get(name string) (string, bool) {
+	for _, entry := range ps {
+		if entry.Key == name {
+			return entry.Value, true
+		}
+	}
+	return "", false
+}
+
+// ByName 返回第一个键名匹配的参数值，没有找到时返回空字符串
+func (ps Params) ByName(name string) (va string) {
+	va, _ = ps.Get(name)
+	return
+}
+
 // 路由树
 type methodTree struct {
 	method string // HTTP 方法
@@ -45,18 +65,143 @@ func (trees methodTrees) get(method string) *node {
 	return nil
 }
 
+// with 返回一个替换了 method 对应路由树的 methodTrees 副本，root 为 nil 时删除该路由树，原 methodTrees 不会被修改
+func (trees methodTrees) with(method string, root *node) methodTrees {
+	newTrees := slices.Clone(trees)
+	for i, tree := range newTrees {
+		if tree.method != method {
+			continue
+		}
+		if root == nil {
+			return slices.Delete(newTrees, i, i+1)
+		}
+		newTrees[i].root = root
+		return newTrees
+	}
+	if root == nil {
+		return newTrees
+	}
+	return append(newTrees, methodTree{method: method, root: root})
+}
+
 // addChild 方法用于向节点中添加一个子节点
-// 如果当前节点中已经存在通配符节点，那么通配符节点一定会在children 的最后一个位置，那么插入的节点就会在通配符节点之前
-// 否则直接添加在 children 的末尾
+// 通配符子节点位于 children 的末尾，插入的静态子节点会在所有通配符子节点之前，调用前 indices 中已经添加了静态子节点的首字符
+// 同一位置的参数子节点按照注册顺序排列，没有约束的参数子节点始终位于最后，查找时最后尝试
 func (n *node) addChild(child *node) {
-	if n.wildChild && len(n.children) > 0 {
-		wildChild := n.children[len(n.children)-1]
-		n.children = append(n.children[:len(n.children)-1], child, wildChild)
-	} else {
+	switch {
+	case child.nType == param:
+		i := len(n.children)
+		if n.wildChild && wildcardConstraint(n.children[i-1].path) == "" {
+			i--
+		}
+		n.children = slices.Insert(n.children, i, child)
+	case n.wildChild:
+		n.children = slices.Insert(n.children, len(n.indices)-1, child)
+	default:
 		n.children = append(n.children, child)
 	}
 }
 
+// wildChildren 返回节点的通配符子节点，没有通配符子节点时返回 nil
+// 静态子节点和 indices 一一对应，位于 children 的前面，之后的子节点都是通配符子节点
+func (n *node) wildChildren() []*node {
+	if !n.wildChild {
+		return nil
+	}
+	return n.children[len(n.indices):]
+}
+
+// wildChildFor 返回和 path 开头的通配符完全相同的通配符子节点，不存在时返回 nil
+// 通配符之后只能是路径结尾、下一段的 '/' 或者 '.' 开头的后缀，例如 ":name" 不会匹配 ":names" 和 ":name<int>"
+func (n *node) wildChildFor(path string) *node {
+	for _, child := range n.wildChildren() {
+		if strings.HasPrefix(path, child.path) &&
+			(len(path) == len(child.path) || path[len(child.path)] == '/' || path[len(child.path)] == '.') {
+			return child
+		}
+	}
+	return nil
+}
+
+// clone 深拷贝以 n 为根的路由树，处理链和约束匹配函数不会被修改，因此在新旧路由树之间共享
+func (n *node) clone() *node {
+	cn := *n
+	cn.children = make([]*node, len(n.children))
+	for i, child := range n.children {
+		cn.children[i] = child.clone()
+	}
+	return &cn
+}
+
+// routes 深度优先遍历路由树，将所有注册了处理链的节点追加到 routes 中
+func (n *node) routes(routes []*node) []*node {
+	if len(n.handlers) > 0 {
+		routes = append(routes, n)
+	}
+	for _, child := range n.children {
+		routes = child.routes(routes)
+	}
+	return routes
+}
+
+// findRoute 返回路由树中完整路径为 fullPath 且注册了处理链的节点，不存在时返回 nil
+func (n *node) findRoute(fullPath string) *node {
+	for _, route := range n.routes(nil) {
+		if route.fullPath == fullPath {
+			return route
+		}
+	}
+	return nil
+}
+
+// walkPath 沿着已经添加到路由树中的路径 path 遍历路由树，依次对经过的节点调用 visit
+// 返回 path 结束处的节点，path 不在路由树中时返回 nil
+func (n *node) walkPath(path string, visit func(n *node)) *node {
+walk:
+	for {
+		if !strings.HasPrefix(path, n.path) {
+			return nil
+		}
+		visit(n)
+
+		path = path[len(n.path):]
+		if path == "" {
+			return n
+		}
+
+		// 路径中的通配符不会出现在 indices 中，没有首字符相同的子节点时进入相同的通配符子节点
+		for i, c := range []byte(n.indices) {
+			if c == path[0] {
+				n = n.children[i]
+				continue walk
+			}
+		}
+		if n = n.wildChildFor(path); n == nil {
+			return nil
+		}
+	}
+}
+
+// slashChild 返回参数节点中以 '/' 开头的子节点，即参数所在段之后的下一段，不存在时返回 nil
+func (n *node) slashChild() *node {
+	for i, c := range []byte(n.indices) {
+		if c == '/' {
+			return n.children[i]
+		}
+	}
+	return nil
+}
+
+// suffixChild 返回参数节点中以 '.' 开头的后缀子节点，例如 ":name.:ext" 中的 ".:ext"，不存在时返回 nil
+func (n *node) suffixChild() *node {
+	for i, c := range []byte(n.indices) {
+		if c == '.' {
+			return n.children[i]
+		}
+	}
+	return nil
+}
+
 // countParams 用来计算路径中参数的数量， 包括用 ":" 开头的参数和 "*" 通配符参数
 func countParams(path string) uint16 {
 	var n uint16
@@ -84,24 +229,185 @@ const (
 
 // 路由树上的节点
 type node struct {
-	path      string       // 当前节点的段路径，例如 "/users" 或 ":id" 或 "*filepath"
-	indices   string       // 每个子节点path的首字符，顺序和children一致
-	wildChild bool         // 是否包含通配符子节点，通配符子节点是指 path 以 ":" 或 "*" 开头的子节点，如果为true，那么通配符的子节点一定会是children 中的最后一个节点
-	nType     nodeType     // 节点类型
-	priority  int          // 经过该节点的路径数量，该数量会影响该 node 在父 node 的 children 中的顺序，数量越大，在父 node 的 children 中越靠前
-	children  []*node      // 子节点
-	handlers  HandlerChain // 该节点对应的handler处理链
-	fullPath  string       // 完整路径，所有父节点的路径 + 当前节点的路径的拼接
+	path      string            // 当前节点的段路径，例如 "/users" 或 ":id" 或 "*filepath"
+	indices   string            // 每个子节点path的首字符，顺序和children一致
+	wildChild bool              // 是否包含通配符子节点，通配符子节点是指 path 以 ":" 或 "*" 开头的子节点，如果为true，那么通配符子节点一定位于 children 的末尾
+	nType     nodeType          // 节点类型
+	priority  int               // 经过该节点的路径数量，该数量会影响该 node 在父 node 的 children 中的顺序，数量越大，在父 node 的 children 中越靠前
+	children  []*node           // 子节点
+	handlers  HandlerChain      // 该节点对应的handler处理链
+	fullPath  string            // 完整路径，所有父节点的路径 + 当前节点的路径的拼接
+	match     func(string) bool // 参数节点的约束匹配函数，例如 ":id<int>" 中 int 对应的匹配函数，没有约束时为 nil
+}
+
+// longestCommonPrefix 返回两个字符串的最长公共前缀的长度
+func longestCommonPrefix(a, b string) int {
+	i := 0
+	max := min(len(a), len(b))
+	for i < max && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// incrementChildPrio 用于增加第 pos 个子节点的优先级，并在必要时将其向前移动
+// 优先级越高的子节点越靠前，这样查找时可以优先匹配到经过路由最多的分支
+// 返回子节点移动后新的位置
+func (n *node) incrementChildPrio(pos int) int {
+	cs := n.children
+	cs[pos].priority++
+	prio := cs[pos].priority
+
+	// 将子节点向前移动，直到前一个节点的优先级不小于当前节点
+	newPos := pos
+	for ; newPos > 0 && cs[newPos-1].priority < prio; newPos-- {
+		cs[newPos-1], cs[newPos] = cs[newPos], cs[newPos-1]
+	}
+
+	// 同步调整 indices 中首字符的顺序，保证和 children 一一对应
+	if newPos != pos {
+		n.indices = n.indices[:newPos] + // 未移动的前缀部分
+			n.indices[pos:pos+1] + // 被移动的首字符
+			n.indices[newPos:pos] + n.indices[pos+1:] // 除 pos 外的剩余部分
+	}
+
+	return newPos
 }
 
 // addRoute 方法用于在当前节点下添加一个路由
+// 注意：该方法不是并发安全的
 func (n *node) addRoute(path string, handlers HandlerChain) {
+	fullPath := path
+	n.priority++
+
+	// 空树，直接插入子节点，并将当前节点标记为根节点
+	if len(n.path) == 0 && len(n.children) == 0 {
+		n.insertChild(path, fullPath, handlers)
+		n.nType = root
+		return
+	}
+
+	// parentFullPathIndex 记录已经走过的节点路径长度之和，用于分裂节点时计算 fullPath
+	parentFullPathIndex := 0
+
+walk:
+	for {
+		// 查找最长公共前缀
+		// 由于已有节点的 path 中不会包含通配符，所以公共前缀中也不会包含 ':' 和 '*'
+		i := longestCommonPrefix(path, n.path)
+
+		// 公共前缀比当前节点的 path 短，需要分裂当前节点
+		// 例如当前节点为 "/users"，新路径为 "/uploads"，则分裂为 "/u" -> ["sers", "ploads"]
+		if i < len(n.path) {
+			child := node{
+				path:      n.path[i:],
+				wildChild: n.wildChild,
+				nType:     static,
+				indices:   n.indices,
+				children:  n.children,
+				handlers:  n.handlers,
+				priority:  n.priority - 1,
+				fullPath:  n.fullPath,
+			}
+
+			n.children = []*node{&child}
+			// 使用 []byte 转换，保证 unicode 字符被正确地按字节处理
+			n.indices = bytesconv.BytesToString([]byte{n.path[i]})
+			n.path = path[:i]
+			n.handlers = nil
+			n.wildChild = false
+			n.fullPath = fullPath[:parentFullPathIndex+i]
+		}
+
+		// 新路径比公共前缀长，需要将剩余部分作为当前节点的子节点
+		if i < len(path) {
+			path = path[i:]
+			c := path[0]
+
+			// 检查是否存在首字符相同的子节点，存在则进入该子节点继续查找
+			for i, max := 0, len(n.indices); i < max; i++ {
+				if c == n.indices[i] {
+					parentFullPathIndex += len(n.path)
+					i = n.incrementChildPrio(i)
+					n = n.children[i]
+					continue walk
+				}
+			}
+
+			// 否则插入一个新的子节点
+			if c != ':' && c != '*' && n.nType != catchAll {
+				// 使用 []byte 转换，保证 unicode 字符被正确地按字节处理
+				n.indices += bytesconv.BytesToString([]byte{c})
+				child := &node{
+					fullPath: fullPath,
+				}
+				n.addChild(child)
+				n.incrementChildPrio(len(n.indices) - 1)
+				n = child
+			} else if n.wildChild {
+				// 插入的是通配符节点，存在相同的通配符子节点时进入该节点继续查找，不允许在 catchAll 节点下添加子节点
+				if child := n.wildChildFor(path); child != nil && child.nType != catchAll {
+					n = child
+					n.priority++
+					continue walk
+				}
+
+				// 同一位置可以存在多个参数节点，只要它们的约束互不相同，例如 ":id<int>" 和 ":slug<alpha>"
+				// 约束相同（包括都没有约束）的参数节点以及 catchAll 节点会产生冲突
+				wildcard, _, _ := findWildCard(path)
+				wilds := n.wildChildren()
+				i := slices.IndexFunc(wilds, func(w *node) bool {
+					return c != ':' || w.nType != param || wildcardConstraint(w.path) == wildcardConstraint(wildcard)
+				})
+				if i < 0 {
+					n.insertChild(path, fullPath, handlers)
+					return
+				}
+
+				// 通配符冲突
+				n = wilds[i]
+				pathSeg := path
+				if n.nType != catchAll {
+					pathSeg = strings.SplitN(pathSeg, "/", 2)[0]
+				}
+				prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path
+				msg := "'" + pathSeg +
+					"' in new path '" + fullPath +
+					"' conflicts with existing wildcard '" + n.path +
+					"' in existing prefix '" + prefix +
+					"'"
+				panic(&RouteConflictError{
+					Kind:         ConflictWildcard,
+					NewPath:      fullPath,
+					ExistingPath: n.fullPath,
+					Segment:      pathSeg,
+					msg:          msg,
+				})
+			}
 
+			n.insertChild(path, fullPath, handlers)
+			return
+		}
+
+		// 新路径和当前节点的 path 完全一致，将处理链设置到当前节点上
+		if n.handlers != nil {
+			panic(&RouteConflictError{
+				Kind:         ConflictDuplicate,
+				NewPath:      fullPath,
+				ExistingPath: n.fullPath,
+				Segment:      n.path,
+				msg:          "handlers are already registered for path '" + fullPath + "'",
+			})
+		}
+		n.handlers = handlers
+		n.fullPath = fullPath
+		return
+	}
 }
 
 // findWildCard 用于在路径中查找通配符 "*"，返回通配符的字符串、位置和是否有效
 // wildcard: 通配符
-// wildcard: 通配符字符串，(: 或 * 以及后面的字符串，到 / 或到末尾，)
+// wildcard: 通配符字符串，(: 或 * 以及后面的字符串，到 / 或到末尾，)，包括参数约束，例如 ":id<int>"
 // n: 通配符在路径中的位置
 // valid: 是否有效，true 表示有效，false 表示无效（例如出现了多个通配符 "/users/:id:name"）
 // 如果没有找到，返回空字符串和 -1
@@ -114,15 +420,28 @@ func findWildCard(path string) (wildcard string, n int, valid bool) {
 
 		// 寻找通配符的结束位置
 		// 1. 如果找到 /, 则通配符到此结束
-		// 2. 如果遇到了 : 或 *, 则无效，则次通配符不合法
-		// 3. 如果循环完没有遇到上述任何一个情况。则通配符到路径的末尾结束
+		// 2. 如果参数通配符 : 后面遇到了 ., 则参数到此结束，. 及后面的内容是同一段中的后缀，例如 ":name.:ext"
+		// 3. 如果遇到了 : 或 *, 则无效，则次通配符不合法
+		// 4. 如果遇到了 <, 则跳过 <> 中的参数约束，约束（例如正则表达式）中可能会包含 ':' 和 '*' 等字符
+		// 5. 如果循环完没有遇到上述任何一个情况。则通配符到路径的末尾结束
 		valid = true
-		for end, c := range []byte(path[start+1:]) {
-			switch c {
+		for end := start + 1; end < len(path); end++ {
+			switch path[end] {
 			case '/':
-				return path[start : start+1+end], start, valid
+				return path[start:end], start, valid
+			case '.':
+				if c == ':' {
+					return path[start:end], start, valid
+				}
 			case ':', '*':
 				valid = false
+			case '<':
+				// 约束没有闭合时，通配符一直到路径末尾，由 insertChild 检查约束是否合法
+				closing := strings.IndexByte(path[end:], '>')
+				if closing < 0 {
+					return path[start:], start, valid
+				}
+				end += closing
 			}
 		}
 		return path[start:], start, valid
@@ -144,13 +463,24 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlerChain)
 
 		// 通配符不合法，直接 panic
 		if !valid {
-			panic("only one wildcard per path segment is allowed, has: '" +
-				wildcard + "' in path '" + fullPath + "'")
+			panic(invalidPathError(fullPath, wildcard, "only one wildcard per path segment is allowed unless separated by a '.' suffix, has: '"+
+				wildcard+"' in path '"+fullPath+"'"))
 		}
 
 		// 如果存在通配符，那么至少有两个字符，一个通配符号 + 至少一个字符
-		if len(wildcard) < 2 {
-			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
+		if len(wildcardName(wildcard)) == 0 {
+			panic(invalidPathError(fullPath, wildcard, "wildcards must be named with a non-empty name in path '"+fullPath+"'"))
+		}
+
+		// 检查参数约束是否合法，约束必须以 '>' 结尾且不能为空，catch-all 通配符不支持约束
+		if c := strings.IndexByte(wildcard, '<'); c >= 0 {
+			if wildcard[0] == '*' {
+				panic(invalidPathError(fullPath, wildcard, "catch-all wildcards can not have a constraint in path '"+fullPath+"'"))
+			}
+			if wildcard[len(wildcard)-1] != '>' || c+2 == len(wildcard) {
+				panic(invalidPathError(fullPath, wildcard, "invalid constraint in wildcard '"+wildcard+"' in path '"+fullPath+
+					"', constraints must be non-empty and close the wildcard with '>'"))
+			}
 		}
 
 		// 通配符为 : ，说明是一个参数节点
@@ -169,13 +499,15 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlerChain)
 			}
 
 			n.addChild(child)
-			n.wildChild = true // 标记当前节点包含通配符子节点，通配符子节点位于 children 的最后一个位置
+			n.wildChild = true // 标记当前节点包含通配符子节点，通配符子节点位于 children 的末尾
 			n = child
 			n.priority++
 
 			// 如果通配符后面还有内容，将 path 的内容修改为参数后面的内容，然后新建一个 path 为空的节点，继续循环
+			// 后面的内容以 '/' 或者 '.' 开头，记录在参数节点的 indices 中，以便区分后缀子节点和下一段的子节点
 			if len(wildcard) < len(path) {
 				path = path[len(wildcard):]
+				n.indices = path[:1]
 				child := &node{
 					priority: 1,
 					fullPath: fullPath,
@@ -187,34 +519,41 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlerChain)
 
 			// 否则，说明通配符是路径的最后一个节点，直接赋值 handlers
 			n.handlers = handlers
-			break
+			return
 		}
 
 		// 运行到此处，说明通配符是 *
 		// 检查 * 通配符是否是路径的最后一个节点
 		if i+len(wildcard) != len(path) {
-			panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
+			panic(invalidPathError(fullPath, wildcard, "catch-all routes are only allowed at the end of the path in path '"+fullPath+"'"))
 		}
 
 		// 节点的路径结尾是 "/", 说明后面还有内容，会导致路径冲突不合法
 		// 例如存在 /api/users/:id 后添加 /api/*filename
 		if len(n.path) > 0 && n.path[len(n.path)-1] == '/' {
-			pathSeg := ""
+			pathSeg, existingPath := "", n.fullPath
 			if len(n.children) != 0 {
 				pathSeg = strings.SplitN(n.children[0].path, "/", 2)[0]
+				existingPath = n.children[0].fullPath
 			}
-			panic("catch-all wildcard '" + path +
-				"' in new path '" + fullPath +
-				"' conflicts with existing path segment '" + pathSeg +
-				"' in existing prefix '" + n.path + pathSeg +
-				"'")
+			panic(&RouteConflictError{
+				Kind:         ConflictCatchAll,
+				NewPath:      fullPath,
+				ExistingPath: existingPath,
+				Segment:      pathSeg,
+				msg: "catch-all wildcard '" + path +
+					"' in new path '" + fullPath +
+					"' conflicts with existing path segment '" + pathSeg +
+					"' in existing prefix '" + n.path + pathSeg +
+					"'",
+			})
 		}
 
-		// 检查通配符前面的符号是否是 /
-		i--
-		if path[i] != '/' {
-			panic("no / before catch-all in path '" + fullPath + "'")
+		// 检查通配符前面的符号是否是 /，通配符位于剩余路径开头时前面的 "/" 已经属于父节点，同样不合法
+		if i == 0 || path[i-1] != '/' {
+			panic(invalidPathError(fullPath, wildcard, "no / before catch-all in path '"+fullPath+"'"))
 		}
+		i--
 
 		// 把当前的空节点的path设置为通配符前 / 前面的内容
 		n.path = path[:i]
@@ -251,3 +590,586 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlerChain)
 	n.handlers = handlers
 	n.fullPath = fullPath
 }
+
+// nodeValue 是 getValue 的返回值，存储路由查找的结果
+type nodeValue struct {
+	handlers HandlerChain // 匹配到的路由处理链，未匹配到时为 nil
+	params   *Params      // 匹配到的 URL 参数
+	tsr      bool         // trailing slash redirect，是否建议重定向到添加或去除末尾 "/" 的路径
+	fullPath string       // 匹配到的路由的完整路径模板，例如 "/users/:id"
+}
+
+// skippedNode 用于记录查找过程中跳过的通配符分支，当静态分支或者前一个参数分支匹配失败时，可以回溯到该节点重新尝试通配符分支
+type skippedNode struct {
+	path        string // 回溯时需要重新匹配的路径
+	node        *node  // 回溯的节点，回溯时只会尝试该节点的通配符子节点
+	wild        int    // 回溯时尝试的第一个通配符子节点在 children 中的位置
+	paramsCount int16  // 回溯时已经匹配到的参数数量，用于截断 Params
+}
+
+// popSkippedNode 从后向前弹出 skippedBase 之后的跳过节点，返回第一个可以重新匹配 path 的节点，没有找到时返回 nil
+func popSkippedNode(skippedNodes *[]skippedNode, skippedBase int, path string) *skippedNode {
+	for length := len(*skippedNodes); length > skippedBase; length-- {
+		skipped := &(*skippedNodes)[length-1]
+		*skippedNodes = (*skippedNodes)[:length-1]
+		if strings.HasSuffix(skipped.path, path) {
+			return skipped
+		}
+	}
+	return nil
+}
+
+// getValue 根据给定的路径查找注册的处理链
+// 路径中的参数值会被写入到 params 中，params 的容量由 路由表的 maxParams 决定，以避免内存分配
+// 如果没有找到处理链，但存在添加或去除末尾 "/" 的路由，则会返回 tsr 为 true 的建议
+// unescape 为 true 时，会对参数值进行 URL 解码
+func (n *node) getValue(path string, params *Params, skippedNodes *[]skippedNode, unescape bool) (value nodeValue) {
+	return n.lookup(path, params, skippedNodes, 0, unescape)
+}
+
+// lookup 是 getValue 的实现，skippedBase 之前的跳过节点属于外层的查找，回溯时不会使用
+// 匹配参数后缀时会递归调用 lookup，后缀只能在当前参数节点之下匹配，不能回溯到外层的分支
+func (n *node) lookup(path string, params *Params, skippedNodes *[]skippedNode, skippedBase int, unescape bool) (value nodeValue) {
+	// 递归调用时 params 中已经存在外层匹配到的参数，参数计数需要从已有的参数数量开始
+	var globalParamsCount int16
+	if params != nil {
+		globalParamsCount = int16(len(*params))
+	}
+
+	// wild 大于 0 时表示当前节点是回溯得到的节点，静态子节点已经尝试过了，只需要从 children 的 wild 位置开始尝试通配符子节点
+	wild := 0
+
+walk: // 外层循环，用于遍历路由树
+	for {
+		prefix := n.path
+		if len(path) > len(prefix) {
+			if path[:len(prefix)] == prefix {
+				skippedPath := path // 回溯时需要重新匹配的路径，即当前节点的 path 加上剩余的路径
+				path = path[len(prefix):]
+
+				// 首先通过 indices 尝试匹配所有的非通配符子节点，回溯得到的节点会跳过这一步
+				idxc := path[0]
+				indices := n.indices
+				if wild > 0 {
+					indices = ""
+				}
+				for i, c := range []byte(indices) {
+					if c == idxc {
+						// 当前节点存在通配符子节点，记录跳过的节点，以便静态分支匹配失败时回溯
+						// 直接记录原节点而不是拷贝，避免查找过程中的内存分配
+						if n.wildChild {
+							*skippedNodes = append(*skippedNodes, skippedNode{
+								path:        skippedPath,
+								node:        n,
+								wild:        len(n.indices),
+								paramsCount: globalParamsCount,
+							})
+						}
+
+						n = n.children[i]
+						continue walk
+					}
+				}
+
+				if !n.wildChild {
+					// 当前节点没有可以匹配的子节点
+					// 如果剩余路径只有 "/" 且当前节点存在处理链，则建议重定向到不带末尾 "/" 的路径，否则回溯到最近一个有效的跳过节点
+					if value.tsr = path == "/" && n.handlers != nil; !value.tsr {
+						if skipped := popSkippedNode(skippedNodes, skippedBase, path); skipped != nil {
+							path, n, wild = skipped.path, skipped.node, skipped.wild
+							if value.params != nil {
+								*value.params = (*value.params)[:skipped.paramsCount]
+							}
+							globalParamsCount = skipped.paramsCount
+							continue walk
+						}
+					}
+					return value
+				}
+
+				// 处理通配符子节点，通配符子节点位于 children 的末尾
+				// 存在多个约束不同的参数子节点时依次尝试，记录下一个参数子节点，当前参数分支匹配失败时回溯
+				if wild == 0 {
+					wild = len(n.indices)
+				}
+				if wild+1 < len(n.children) {
+					*skippedNodes = append(*skippedNodes, skippedNode{
+						path:        skippedPath,
+						node:        n,
+						wild:        wild + 1,
+						paramsCount: globalParamsCount,
+					})
+				}
+				n, wild = n.children[wild], 0
+				globalParamsCount++
+
+				switch n.nType {
+				case param:
+					// 查找参数的结束位置，'/' 或者路径末尾
+					end := 0
+					for end < len(path) && path[end] != '/' {
+						end++
+					}
+
+					// 参数节点存在以 '.' 开头的后缀子节点时（例如 "/files/:name.:ext"），优先尝试后缀匹配
+					// 从左到右依次在段内的每个 '.' 处截断参数值，使用后缀子节点匹配剩余的路径，返回第一个匹配成功的结果
+					// 所有的截断位置都匹配失败时，再将整个段作为参数值处理
+					if suffix := n.suffixChild(); suffix != nil {
+						for k := 1; k < end; k++ {
+							if path[k] != '.' {
+								continue
+							}
+
+							val := path[:k]
+							if unescape {
+								if v, err := url.QueryUnescape(val); err == nil {
+									val = v
+								}
+							}
+							if n.match != nil && !n.match(val) {
+								continue
+							}
+
+							saved := 0
+							if params != nil {
+								if value.params == nil {
+									value.params = params
+								}
+								saved = len(*params)
+								*params = append(*params, Param{Key: wildcardName(n.path), Value: val})
+							}
+
+							// 后缀子节点的查找不能回溯到当前参数节点之外的分支，匹配失败后丢弃后缀查找中记录的跳过节点
+							base := len(*skippedNodes)
+							if sv := suffix.lookup(path[k:], params, skippedNodes, base, unescape); sv.handlers != nil {
+								sv.params = value.params
+								return sv
+							}
+							*skippedNodes = (*skippedNodes)[:base]
+
+							if params != nil {
+								*params = (*params)[:saved]
+							}
+						}
+					}
+
+					val := path[:end]
+					if unescape {
+						if v, err := url.QueryUnescape(val); err == nil {
+							val = v
+						}
+					}
+
+					// 参数值不满足约束，回溯到最近一个有效的跳过节点，尝试其他分支
+					if n.match != nil && !n.match(val) {
+						if skipped := popSkippedNode(skippedNodes, skippedBase, path); skipped != nil {
+							path, n, wild = skipped.path, skipped.node, skipped.wild
+							if value.params != nil {
+								*value.params = (*value.params)[:skipped.paramsCount]
+							}
+							globalParamsCount = skipped.paramsCount
+							continue walk
+						}
+						return value
+					}
+
+					// 保存参数值
+					if params != nil {
+						// 容量不足时重新分配
+						if cap(*params) < int(globalParamsCount) {
+							newParams := make(Params, len(*params), globalParamsCount)
+							copy(newParams, *params)
+							*params = newParams
+						}
+
+						if value.params == nil {
+							value.params = params
+						}
+						// 在预分配的容量内扩展切片
+						i := len(*value.params)
+						*value.params = (*value.params)[:i+1]
+						(*value.params)[i] = Param{
+							Key:   wildcardName(n.path),
+							Value: val,
+						}
+					}
+
+					// 参数后面还有路径，需要继续向下查找
+					if end < len(path) {
+						if child := n.slashChild(); child != nil {
+							path = path[end:]
+							n = child
+							continue walk
+						}
+
+						// 没有可以继续查找的子节点，不存在 tsr 建议时回溯到最近一个有效的跳过节点，尝试其他分支
+						if value.tsr = len(path) == end+1; !value.tsr {
+							if skipped := popSkippedNode(skippedNodes, skippedBase, path); skipped != nil {
+								path, n, wild = skipped.path, skipped.node, skipped.wild
+								if value.params != nil {
+									*value.params = (*value.params)[:skipped.paramsCount]
+								}
+								globalParamsCount = skipped.paramsCount
+								continue walk
+							}
+						}
+						return value
+					}
+
+					if value.handlers = n.handlers; value.handlers != nil {
+						value.fullPath = n.fullPath
+						return value
+					}
+
+					if child := n.slashChild(); child != nil {
+						// 没有找到处理链，检查是否存在添加末尾 "/" 的路由，用于 tsr 建议
+						value.tsr = (child.path == "/" && child.handlers != nil) || (child.path == "" && child.indices == "/")
+					}
+
+					// 参数节点没有处理链，不存在 tsr 建议时回溯到最近一个有效的跳过节点，尝试其他分支
+					if !value.tsr {
+						if skipped := popSkippedNode(skippedNodes, skippedBase, path); skipped != nil {
+							path, n, wild = skipped.path, skipped.node, skipped.wild
+							if value.params != nil {
+								*value.params = (*value.params)[:skipped.paramsCount]
+							}
+							globalParamsCount = skipped.paramsCount
+							continue walk
+						}
+					}
+					return value
+
+				case catchAll:
+					// 保存参数值
+					if params != nil {
+						// 容量不足时重新分配
+						if cap(*params) < int(globalParamsCount) {
+							newParams := make(Params, len(*params), globalParamsCount)
+							copy(newParams, *params)
+							*params = newParams
+						}
+
+						if value.params == nil {
+							value.params = params
+						}
+						// 在预分配的容量内扩展切片
+						i := len(*value.params)
+						*value.params = (*value.params)[:i+1]
+						val := path
+						if unescape {
+							if v, err := url.QueryUnescape(path); err == nil {
+								val = v
+							}
+						}
+						(*value.params)[i] = Param{
+							Key:   n.path[2:],
+							Value: val,
+						}
+					}
+
+					value.handlers = n.handlers
+					value.fullPath = n.fullPath
+					return value
+
+				default:
+					panic("invalid node type")
+				}
+			}
+		}
+
+		if path == prefix {
+			// 当前节点没有处理链，且路径不是 "/"，需要回溯到最近一个有效的跳过节点
+			if n.handlers == nil && path != "/" {
+				if skipped := popSkippedNode(skippedNodes, skippedBase, path); skipped != nil {
+					path, n, wild = skipped.path, skipped.node, skipped.wild
+					if value.params != nil {
+						*value.params = (*value.params)[:skipped.paramsCount]
+					}
+					globalParamsCount = skipped.paramsCount
+					continue walk
+				}
+			}
+
+			// 到达了路径对应的节点，检查该节点是否注册了处理链
+			if value.handlers = n.handlers; value.handlers != nil {
+				value.fullPath = n.fullPath
+				return value
+			}
+
+			// 该节点没有处理链，但存在通配符子节点，说明存在添加末尾 "/" 的路由
+			if path == "/" && n.wildChild && n.nType != root {
+				value.tsr = true
+				return value
+			}
+
+			if path == "/" && n.nType == static {
+				value.tsr = true
+				return value
+			}
+
+			// 没有找到处理链，检查是否存在添加末尾 "/" 的路由，用于 tsr 建议
+			for i, c := range []byte(n.indices) {
+				if c == '/' {
+					n = n.children[i]
+					value.tsr = (len(n.path) == 1 && n.handlers != nil) ||
+						(n.nType == catchAll && n.children[0].handlers != nil)
+					return value
+				}
+			}
+
+			return value
+		}
+
+		// 没有找到，如果存在添加末尾 "/" 的路由，则建议重定向
+		value.tsr = path == "/" ||
+			(len(prefix) == len(path)+1 && prefix[len(path)] == '/' &&
+				path == prefix[:len(prefix)-1] && n.handlers != nil)
+
+		// 回溯到最近一个有效的跳过节点
+		if !value.tsr && path != "/" {
+			if skipped := popSkippedNode(skippedNodes, skippedBase, path); skipped != nil {
+				path, n, wild = skipped.path, skipped.node, skipped.wild
+				if value.params != nil {
+					*value.params = (*value.params)[:skipped.paramsCount]
+				}
+				globalParamsCount = skipped.paramsCount
+				continue walk
+			}
+		}
+
+		return value
+	}
+}
+
+// findCaseInsensitivePath 对给定的路径进行大小写不敏感的查找，并尝试修正末尾的 "/"
+// 返回修正了大小写的路径（以及末尾 "/" 被修正后的路径），以及是否查找成功
+func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) ([]byte, bool) {
+	const stackBufSize = 128
+
+	// 大多数情况下使用栈上的静态大小缓冲区，路径过长时再在堆上分配
+	buf := make([]byte, 0, stackBufSize)
+	if length := len(path) + 1; length > stackBufSize {
+		buf = make([]byte, 0, length)
+	}
+
+	ciPath := n.findCaseInsensitivePathRec(
+		path,
+		buf,       // 预分配足够的内存存放新路径
+		[4]byte{}, // 空的 rune 缓冲区
+		fixTrailingSlash,
+	)
+
+	return ciPath, ciPath != nil
+}
+
+// shiftNRuneBytes 将 rune 缓冲区中的字节向左移动 n 个位置
+func shiftNRuneBytes(rb [4]byte, n int) [4]byte {
+	switch n {
+	case 0:
+		return rb
+	case 1:
+		return [4]byte{rb[1], rb[2], rb[3], 0}
+	case 2:
+		return [4]byte{rb[2], rb[3]}
+	case 3:
+		return [4]byte{rb[3]}
+	default:
+		return [4]byte{}
+	}
+}
+
+// findCaseInsensitivePathRec 是 findCaseInsensitivePath 使用的递归查找函数
+// rb 中存放的是当前 rune 中还未处理的字节，因为一个 rune 的多个字节可能会被拆分到多个节点中
+func (n *node) findCaseInsensitivePathRec(path string, ciPath []byte, rb [4]byte, fixTrailingSlash bool) []byte {
+	npLen := len(n.path)
+
+walk: // 外层循环，用于遍历路由树
+	for len(path) >= npLen && (npLen == 0 || strings.EqualFold(path[1:npLen], n.path[1:])) {
+		// 将公共前缀添加到结果中
+		oldPath := path
+		path = path[npLen:]
+		ciPath = append(ciPath, n.path...)
+
+		if len(path) == 0 {
+			// 到达了路径对应的节点，检查该节点是否注册了处理链
+			if n.handlers != nil {
+				return ciPath
+			}
+
+			// 没有找到处理链，尝试添加末尾的 "/" 修正路径
+			if fixTrailingSlash {
+				for i, c := range []byte(n.indices) {
+					if c == '/' {
+						n = n.children[i]
+						if (len(n.path) == 1 && n.handlers != nil) ||
+							(n.nType == catchAll && n.children[0].handlers != nil) {
+							return append(ciPath, '/')
+						}
+						return nil
+					}
+				}
+			}
+			return nil
+		}
+
+		// 当前节点没有通配符子节点，查找下一个子节点并继续向下遍历
+		if !n.wildChild {
+			// 跳过已经处理过的 rune 字节
+			rb = shiftNRuneBytes(rb, npLen)
+
+			if rb[0] != 0 {
+				// 上一个 rune 还没有处理完
+				idxc := rb[0]
+				for i, c := range []byte(n.indices) {
+					if c == idxc {
+						// 继续处理子节点
+						n = n.children[i]
+						npLen = len(n.path)
+						continue walk
+					}
+				}
+			} else {
+				// 处理一个新的 rune
+				var rv rune
+
+				// 查找 rune 的起始位置，rune 最多有 4 个字节，往前 4 个字节一定是另一个 rune
+				var off int
+				for max := min(npLen, 3); off < max; off++ {
+					if i := npLen - off; utf8.RuneStart(oldPath[i]) {
+						// 从缓存的路径中读取 rune
+						rv, _ = utf8.DecodeRuneInString(oldPath[i:])
+						break
+					}
+				}
+
+				// 计算当前 rune 的小写字节
+				lo := unicode.ToLower(rv)
+				utf8.EncodeRune(rb[:], lo)
+
+				// 跳过已经处理过的字节
+				rb = shiftNRuneBytes(rb, off)
+
+				idxc := rb[0]
+				for i, c := range []byte(n.indices) {
+					// 小写字符匹配
+					if c == idxc {
+						// 大写和小写字节可能同时存在于 indices 中，所以这里需要使用递归
+						if out := n.children[i].findCaseInsensitivePathRec(
+							path, ciPath, rb, fixTrailingSlash,
+						); out != nil {
+							return out
+						}
+						break
+					}
+				}
+
+				// 小写没有匹配到，如果大写和小写不同，再尝试大写
+				if up := unicode.ToUpper(rv); up != lo {
+					utf8.EncodeRune(rb[:], up)
+					rb = shiftNRuneBytes(rb, off)
+
+					idxc := rb[0]
+					for i, c := range []byte(n.indices) {
+						// 大写字符匹配
+						if c == idxc {
+							// 继续处理子节点
+							n = n.children[i]
+							npLen = len(n.path)
+							continue walk
+						}
+					}
+				}
+			}
+
+			// 没有找到，如果存在去除末尾 "/" 的路由，则返回去除末尾 "/" 的路径
+			if fixTrailingSlash && path == "/" && n.handlers != nil {
+				return ciPath
+			}
+			return nil
+		}
+
+		// 通配符子节点位于 children 的末尾，和 lookup 一样依次尝试约束不同的参数子节点
+		for _, child := range n.wildChildren() {
+			switch child.nType {
+			case param:
+				if out := child.findCaseInsensitiveParam(path, ciPath, rb, fixTrailingSlash); out != nil {
+					return out
+				}
+			case catchAll:
+				return append(ciPath, path...)
+			default:
+				panic("invalid node type")
+			}
+		}
+		return nil
+	}
+
+	// 没有找到，尝试添加或去除末尾的 "/" 修正路径
+	if fixTrailingSlash {
+		if path == "/" {
+			return ciPath
+		}
+		if len(path)+1 == npLen && n.path[len(path)] == '/' &&
+			strings.EqualFold(path[1:], n.path[1:len(path)]) && n.handlers != nil {
+			return append(ciPath, n.path...)
+		}
+	}
+	return nil
+}
+
+// findCaseInsensitiveParam 是 findCaseInsensitivePathRec 在参数节点 n 上的查找，path 以参数值开头
+func (n *node) findCaseInsensitiveParam(path string, ciPath []byte, rb [4]byte, fixTrailingSlash bool) []byte {
+	// 查找参数的结束位置，'/' 或者路径末尾
+	end := 0
+	for end < len(path) && path[end] != '/' {
+		end++
+	}
+
+	// 和 lookup 一样，参数节点存在后缀子节点时，从左到右依次在段内的每个 '.' 处截断参数值，优先尝试后缀匹配
+	if suffix := n.suffixChild(); suffix != nil {
+		for k := 1; k < end; k++ {
+			if path[k] != '.' || (n.match != nil && !n.match(path[:k])) {
+				continue
+			}
+			if out := suffix.findCaseInsensitivePathRec(
+				path[k:], append(ciPath, path[:k]...), [4]byte{}, fixTrailingSlash,
+			); out != nil {
+				return out
+			}
+		}
+	}
+
+	// 参数值不满足约束
+	if n.match != nil && !n.match(path[:end]) {
+		return nil
+	}
+
+	// 将参数值原样添加到结果中
+	ciPath = append(ciPath, path[:end]...)
+
+	// 参数后面还有路径，需要继续向下查找
+	if end < len(path) {
+		if child := n.slashChild(); child != nil {
+			return child.findCaseInsensitivePathRec(path[end:], ciPath, rb, fixTrailingSlash)
+		}
+
+		// 没有可以继续查找的子节点
+		if fixTrailingSlash && len(path) == end+1 {
+			return ciPath
+		}
+		return nil
+	}
+
+	if n.handlers != nil {
+		return ciPath
+	}
+
+	if fixTrailingSlash {
+		// 没有找到处理链，检查是否存在添加末尾 "/" 的路由
+		if child := n.slashChild(); child != nil && child.path == "/" && child.handlers != nil {
+			return append(ciPath, '/')
+		}
+	}
+
+	return nil
+}
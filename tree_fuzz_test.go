@@ -0,0 +1,213 @@
+package gon
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fuzzSegments 是随机路由中静态段的取值，取值之间存在公共前缀，用于触发节点分裂和回溯
+var fuzzSegments = []string{"a", "ab", "abc", "b", "ba"}
+
+// fuzzValues 是请求路径中参数段的取值，部分取值和静态段相同，用于触发静态段和参数段之间的回溯
+var fuzzValues = []string{"a", "ab", "x", "abc", "b.c"}
+
+// decodeRoutes 将模糊测试的输入解码为一组路由，每个字节表示路由中的一段
+// 同一深度的参数使用相同的名称，减少因为参数名称不同而产生的冲突
+func decodeRoutes(data []byte) []string {
+	var routes []string
+	var b strings.Builder
+	depth := 0
+	end := func(trailingSlash bool) {
+		if b.Len() == 0 || trailingSlash {
+			b.WriteByte('/')
+		}
+		routes = append(routes, b.String())
+		b.Reset()
+		depth = 0
+	}
+
+	for _, c := range data {
+		if len(routes) == 12 {
+			break
+		}
+		switch k := int(c % 8); {
+		case k < len(fuzzSegments):
+			b.WriteString("/" + fuzzSegments[k])
+		case k == 5:
+			b.WriteString("/:p" + string(rune('0'+depth)))
+		case k == 6:
+			b.WriteString("/*rest")
+			end(false)
+			continue
+		default:
+			end(c&8 != 0)
+			continue
+		}
+		if depth++; depth == 5 {
+			end(false)
+		}
+	}
+	if b.Len() > 0 {
+		end(false)
+	}
+	return routes
+}
+
+// concretePaths 将路由模板展开为多个请求路径，参数段和通配符段使用不同的取值，同时加入添加和去除末尾 "/" 的路径
+func concretePaths(route string) []string {
+	var paths []string
+	segs := strings.Split(route[1:], "/")
+	for k := range fuzzValues {
+		concrete := make([]string, len(segs))
+		for i, seg := range segs {
+			switch {
+			case strings.HasPrefix(seg, ":"):
+				concrete[i] = fuzzValues[(k+i)%len(fuzzValues)]
+			case strings.HasPrefix(seg, "*"):
+				concrete[i] = []string{"", "a", "a/b", "ab/"}[k%4]
+			default:
+				concrete[i] = seg
+			}
+		}
+		path := "/" + strings.Join(concrete, "/")
+		paths = append(paths, path, path+"/")
+		if len(path) > 1 {
+			paths = append(paths, strings.TrimSuffix(path, "/"))
+		}
+	}
+	return paths
+}
+
+// naiveMatch 是一个逐个比较所有路由的参考实现，用于检查路由树的查找结果
+// 多个路由同时匹配时，从左到右比较每一段的类型，优先选择静态段，其次是参数段，最后是通配符段
+func naiveMatch(routes []string, path string) (string, Params, bool) {
+	pathSegs := strings.Split(path[1:], "/")
+
+	var best string
+	var bestParams Params
+	var bestKinds []int
+	for _, route := range routes {
+		kinds, params, ok := naiveMatchSegments(strings.Split(route[1:], "/"), pathSegs)
+		if ok && (bestKinds == nil || slices.Compare(kinds, bestKinds) < 0) {
+			best, bestParams, bestKinds = route, params, kinds
+		}
+	}
+	return best, bestParams, bestKinds != nil
+}
+
+// naiveMatchSegments 判断路由的各段是否和路径的各段匹配，返回每一段的类型（0 静态段，1 参数段，2 通配符段）和参数
+func naiveMatchSegments(segs, pathSegs []string) ([]int, Params, bool) {
+	kinds := make([]int, 0, len(segs))
+	var params Params
+	for i, seg := range segs {
+		switch {
+		case strings.HasPrefix(seg, "*"):
+			if i >= len(pathSegs) {
+				return nil, nil, false
+			}
+			params = append(params, Param{Key: seg[1:], Value: "/" + strings.Join(pathSegs[i:], "/")})
+			return append(kinds, 2), params, true
+		case i >= len(pathSegs):
+			return nil, nil, false
+		case strings.HasPrefix(seg, ":"):
+			// 和 Gin 一致，参数值只有在位于路径末尾时不能为空，例如 "/:id/edit" 可以匹配 "//edit"
+			if pathSegs[i] == "" && i == len(pathSegs)-1 {
+				return nil, nil, false
+			}
+			params = append(params, Param{Key: seg[1:], Value: pathSegs[i]})
+			kinds = append(kinds, 1)
+		default:
+			if seg != pathSegs[i] {
+				return nil, nil, false
+			}
+			kinds = append(kinds, 0)
+		}
+	}
+	return kinds, params, len(segs) == len(pathSegs)
+}
+
+// checkAgainstNaive 检查路由树对 path 的查找结果和参考实现是否一致
+func checkAgainstNaive(t *testing.T, tree *node, routes []string, path string) {
+	t.Helper()
+	want, wantParams, found := naiveMatch(routes, path)
+	value := tree.getValue(path, getParams(), getSkippedNodes(), false)
+
+	if value.handlers == nil {
+		// 和 Gin 一致，tsr 建议优先于回溯到通配符分支，且只是根据路由树结构给出的建议，参考实现不模拟这部分行为
+		if found && !value.tsr {
+			t.Fatalf("routes %q: %s not found, want %s", routes, path, want)
+		}
+		return
+	}
+	if !found {
+		t.Fatalf("routes %q: %s matched %s, want no match", routes, path, value.fullPath)
+	}
+	if value.fullPath != want {
+		t.Fatalf("routes %q: %s matched %s, want %s", routes, path, value.fullPath, want)
+	}
+
+	var params Params
+	if value.params != nil {
+		params = *value.params
+	}
+	if len(params) != 0 || len(wantParams) != 0 {
+		if !reflect.DeepEqual(params, wantParams) {
+			t.Fatalf("routes %q: %s got params %v, want %v", routes, path, params, wantParams)
+		}
+	}
+}
+
+// FuzzTreeRoutes 随机生成一组路由插入路由树，检查所有路由展开后的请求路径的查找结果和参考实现一致
+// 和已有路由冲突的路由会被跳过，插入在路由树的副本上进行，插入失败不会影响已经插入的路由
+func FuzzTreeRoutes(f *testing.F) {
+	f.Add([]byte{0, 7, 5, 7, 0, 5, 1, 7})
+	f.Add([]byte{5, 0, 7, 0, 1, 7, 5, 5, 2, 7})
+	f.Add([]byte{1, 6, 1, 0, 7, 5, 15, 3, 4, 7})
+	f.Add([]byte{0, 1, 2, 7, 0, 5, 2, 7, 5, 5, 7, 0, 1, 5, 7})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree := &node{fullPath: "/"}
+		var routes []string
+		for _, route := range decodeRoutes(data) {
+			candidate := tree.clone()
+			if catchPanic(func() { candidate.addRoute(route, fakeHandler(route)) }) == nil {
+				tree = candidate
+				routes = append(routes, route)
+			}
+		}
+
+		for _, route := range routes {
+			for _, path := range concretePaths(route) {
+				checkAgainstNaive(t, tree, routes, path)
+			}
+		}
+		checkPriorities(t, tree)
+	})
+}
+
+// FuzzGithubLookup 使用 GitHub API 的路由集合，检查任意请求路径的查找结果和参考实现一致
+func FuzzGithubLookup(f *testing.F) {
+	tree := &node{fullPath: "/"}
+	var routes []string
+	for _, route := range githubAPI {
+		if route.method == "GET" {
+			tree.addRoute(route.path, fakeHandler(route.path))
+			routes = append(routes, route.path)
+		}
+	}
+
+	for _, route := range routes {
+		f.Add(route)
+	}
+	f.Add("/repos/gin-gonic/gin/git/refs/heads/master")
+	f.Add("/user/starred/gin-gonic/")
+
+	f.Fuzz(func(t *testing.T, path string) {
+		if path == "" || path[0] != '/' {
+			return
+		}
+		checkAgainstNaive(t, tree, routes, path)
+	})
+}
@@ -0,0 +1,150 @@
+package gon
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Name 为路由设置名称，设置名称后可以通过 Engine.URL 根据名称生成路由的 URL
+// 名称在整个 Engine 中必须唯一，重复的名称会触发 panic，返回注册该路由的路由组，可以继续链式注册路由
+//
+//	router.GET("/users/:id", showUser).Name("user.show")
+//	url, err := router.URL("user.show", gon.Param{Key: "id", Value: "42"}) // "/users/42"
+func (route *Route) Name(name string) IRoutes {
+	assert1(name != "", "route name can not be empty")
+
+	route.engine.routesMu.Lock()
+	defer route.engine.routesMu.Unlock()
+	if named, ok := route.engine.namedRoutes[name]; ok {
+		panic("route name '" + name + "' is already used by " + named.method + " '" + named.host + named.path + "'")
+	}
+	route.engine.namedRoutes[name] = route
+	return route.IRoutes
+}
+
+// removeNamedRoutes 删除 host 主机名下请求方式为 method、路径为 paths 中任意一个的路由名称，必须在持有 routesMu 时调用
+// 包含可选参数的路由只删除了部分展开后的路径时同样删除名称，否则名称生成的 URL 可能指向已经删除的路由
+func (engine *Engine) removeNamedRoutes(host, method string, paths []string) {
+	maps.DeleteFunc(engine.namedRoutes, func(_ string, route *Route) bool {
+		if route.method != method || !strings.EqualFold(route.host, host) {
+			return false
+		}
+		return slices.ContainsFunc(expandOptionalPath(route.path), func(p string) bool {
+			return slices.Contains(paths, p)
+		})
+	})
+}
+
+// URL 根据路由名称和参数生成路由的 URL 路径，参数值会进行 URL 编码
+// 所有必选参数都必须提供，参数值需要满足路由中的参数约束
+// 可选参数没有提供时，会省略该参数以及之后的可选参数段
+// 通配符参数的值可以包含 "/"，例如 "/static/*filepath" 提供 filepath 为 "css/app.css" 时生成 "/static/css/app.css"
+// 通过 Engine.Host 注册的路由会生成不包含协议的 URL，主机名中的参数同样使用 params 中的值，例如 "//acme.example.com/users"
+func (engine *Engine) URL(name string, params ...Param) (string, error) {
+	engine.routesMu.Lock()
+	defer engine.routesMu.Unlock()
+
+	route, ok := engine.namedRoutes[name]
+	if !ok {
+		return "", fmt.Errorf("route name '%s' is not registered", name)
+	}
+
+	values := Params(params)
+	// 可选参数展开后的路径从短到长排列，选择所有参数都已提供的最长的路径
+	path := route.path
+	paths := expandOptionalPath(path)
+	for i := len(paths) - 1; i > 0; i-- {
+		if engine.hasAllParams(paths[i], values) {
+			path = paths[i]
+			break
+		}
+		path = paths[i-1]
+	}
+	u, err := engine.buildURL(name, path, values)
+	if err != nil || route.host == "" {
+		return u, err
+	}
+
+	host, err := buildHost(name, route.host, values)
+	if err != nil {
+		return "", err
+	}
+	return "//" + host + u, nil
+}
+
+// buildHost 使用参数值替换主机名模式中的参数，生成主机名
+// 主机名参数只能匹配一个标签，因此参数值不能为空，也不能包含 "."
+func buildHost(name, pattern string, values Params) (string, error) {
+	labels := strings.Split(pattern, ".")
+	for i, label := range labels {
+		if label[0] != ':' {
+			continue
+		}
+		key := label[1:]
+		value, ok := values.Get(key)
+		if !ok {
+			return "", fmt.Errorf("missing value of host parameter '%s' for route '%s'", key, name)
+		}
+		if value == "" || strings.ContainsAny(value, ".:/") {
+			return "", fmt.Errorf("invalid value '%s' of host parameter '%s' for route '%s'", value, key, name)
+		}
+		labels[i] = value
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// hasAllParams 判断是否提供了路径中所有参数的值
+func (engine *Engine) hasAllParams(path string, values Params) bool {
+	for {
+		wildcard, i, _ := findWildCard(path)
+		if i < 0 {
+			return true
+		}
+		if _, ok := values.Get(wildcardName(wildcard)); !ok {
+			return false
+		}
+		path = path[i+len(wildcard):]
+	}
+}
+
+// buildURL 使用参数值替换路径中的参数，生成 URL 路径
+func (engine *Engine) buildURL(name, path string, values Params) (string, error) {
+	var buf strings.Builder
+	for {
+		wildcard, i, _ := findWildCard(path)
+		if i < 0 {
+			buf.WriteString(path)
+			return buf.String(), nil
+		}
+		buf.WriteString(path[:i])
+		path = path[i+len(wildcard):]
+
+		key := wildcardName(wildcard)
+		value, ok := values.Get(key)
+		if !ok {
+			return "", fmt.Errorf("missing value of parameter '%s' for route '%s'", key, name)
+		}
+
+		if wildcard[0] == '*' {
+			// 通配符参数的值以 "/" 开头，而路径中通配符前的 "/" 已经写入
+			value = strings.TrimPrefix(value, "/")
+			segments := strings.Split(value, "/")
+			for j, seg := range segments {
+				segments[j] = url.PathEscape(seg)
+			}
+			buf.WriteString(strings.Join(segments, "/"))
+			continue
+		}
+
+		if value == "" {
+			return "", fmt.Errorf("empty value of parameter '%s' for route '%s'", key, name)
+		}
+		if constraint := wildcardConstraint(wildcard); constraint != "" && !engine.paramMatcher(constraint)(value) {
+			return "", fmt.Errorf("value '%s' of parameter '%s' does not match constraint '%s' for route '%s'", value, key, constraint, name)
+		}
+		buf.WriteString(url.PathEscape(value))
+	}
+}
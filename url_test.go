@@ -0,0 +1,160 @@
+package gon
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	router := New()
+	router.GET("/users/:name", func(c *Context) {}).Name("user")
+	router.GET("/static/*filepath", func(c *Context) {}).Name("static")
+	router.GET("/reports/:year<int>?/:month<int>?", func(c *Context) {}).Name("reports")
+	router.GET("/files/:name.:ext", func(c *Context) {}).Name("file")
+	api := router.Group("/api")
+	api.POST("/orders/:id<uuid>", func(c *Context) {}).Name("order")
+
+	for _, tt := range []struct {
+		name   string
+		params []Param
+		want   string
+	}{
+		{"user", []Param{{"name", "gon"}}, "/users/gon"},
+		{"user", []Param{{"name", "a b/c?d"}}, "/users/a%20b%2Fc%3Fd"},
+		{"user", []Param{{"name", "gon"}, {"unused", "x"}}, "/users/gon"},
+		{"static", []Param{{"filepath", "css/app v1.css"}}, "/static/css/app%20v1.css"},
+		{"static", []Param{{"filepath", "/js/app.js"}}, "/static/js/app.js"},
+		{"static", []Param{{"filepath", ""}}, "/static/"},
+		{"reports", nil, "/reports"},
+		{"reports", []Param{{"year", "2024"}}, "/reports/2024"},
+		{"reports", []Param{{"year", "2024"}, {"month", "5"}}, "/reports/2024/5"},
+		{"reports", []Param{{"month", "5"}}, "/reports"}, // 缺少前面的可选参数时省略之后的可选参数段
+		{"file", []Param{{"name", "report"}, {"ext", "pdf"}}, "/files/report.pdf"},
+		{"order", []Param{{"id", "123e4567-e89b-12d3-a456-426614174000"}}, "/api/orders/123e4567-e89b-12d3-a456-426614174000"},
+	} {
+		got, err := router.URL(tt.name, tt.params...)
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%s, %v) = %q, %v, want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		params []Param
+		errMsg string
+	}{
+		{"unknown", nil, "is not registered"},
+		{"user", nil, "missing value of parameter 'name'"},
+		{"user", []Param{{"name", ""}}, "empty value of parameter 'name'"},
+		{"file", []Param{{"name", "report"}}, "missing value of parameter 'ext'"},
+		{"reports", []Param{{"year", "last"}}, "does not match constraint 'int'"},
+		{"order", []Param{{"id", "42"}}, "does not match constraint 'uuid'"},
+	} {
+		if _, err := router.URL(tt.name, tt.params...); err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("URL(%s, %v): got error %v, want %q", tt.name, tt.params, err, tt.errMsg)
+		}
+	}
+}
+
+func TestURLGeneratedPathsMatchRoutes(t *testing.T) {
+	router := New()
+	router.GET("/users/:name/*rest", func(c *Context) {
+		c.Writer.Header().Set("X-Name", c.Param("name"))
+		c.Writer.Header().Set("X-Rest", c.Param("rest"))
+	}).Name("user")
+
+	url, err := router.URL("user", Param{"name", "a b/c"}, Param{"rest", "x/y z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.UseRawPath = true
+	w := performRequest(router, http.MethodGet, url)
+	if w.Header().Get("X-Name") != "a b/c" || w.Header().Get("X-Rest") != "/x/y z" {
+		t.Errorf("GET %s: got name %q rest %q", url, w.Header().Get("X-Name"), w.Header().Get("X-Rest"))
+	}
+}
+
+func TestNamePanics(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {}).Name("user")
+
+	for name, f := range map[string]func(){
+		"duplicate name": func() { router.GET("/members/:id", func(c *Context) {}).Name("user") },
+		"empty name":     func() { router.GET("/empty", func(c *Context) {}).Name("") },
+	} {
+		if catchPanic(f) == nil {
+			t.Errorf("%s did not panic", name)
+		}
+	}
+
+	// 名称对应的路由不会因为重复的名称而改变
+	if url, err := router.URL("user", Param{"id", "1"}); err != nil || url != "/users/1" {
+		t.Errorf("URL(user) = %q, %v", url, err)
+	}
+}
+
+// TestNameConcurrent 在多个 goroutine 中使用同一个路由组注册路由并设置名称，需要使用 -race 运行才能发现数据竞争
+func TestNameConcurrent(t *testing.T) {
+	router := New()
+	api := router.Group("/api")
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := "/" + string(rune('a'+i))
+			api.GET(path, func(c *Context) {}).Name(path)
+			_, _ = router.URL(path)
+		}()
+	}
+	wg.Wait()
+
+	// 每个名称都对应设置名称的 goroutine 注册的路由
+	for i := range 8 {
+		path := "/" + string(rune('a'+i))
+		if url, err := router.URL(path); err != nil || url != "/api"+path {
+			t.Errorf("URL(%s) = %q, %v", path, url, err)
+		}
+	}
+}
+
+func TestURLHost(t *testing.T) {
+	router := New()
+	router.Host("api.example.com").GET("/users/:id", func(c *Context) {}).Name("api.user")
+	router.Host(":tenant.example.com").Group("/admin").GET("/", func(c *Context) {}).Name("tenant.admin")
+
+	for _, tt := range []struct {
+		name   string
+		params []Param
+		want   string
+	}{
+		{"api.user", []Param{{"id", "42"}}, "//api.example.com/users/42"},
+		{"tenant.admin", []Param{{"tenant", "acme"}}, "//acme.example.com/admin/"},
+	} {
+		got, err := router.URL(tt.name, tt.params...)
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%s, %v) = %q, %v, want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		params []Param
+		errMsg string
+	}{
+		{nil, "missing value of host parameter 'tenant'"},
+		{[]Param{{"tenant", ""}}, "invalid value '' of host parameter 'tenant'"},
+		{[]Param{{"tenant", "a.b"}}, "invalid value 'a.b' of host parameter 'tenant'"},
+	} {
+		if _, err := router.URL("tenant.admin", tt.params...); err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("URL(tenant.admin, %v): got error %v, want %q", tt.params, err, tt.errMsg)
+		}
+	}
+
+	// 生成的 URL 可以匹配到对应主机名下的路由
+	w := performHostRequest(router, http.MethodGet, "acme.example.com", "/admin/")
+	if w.Code != http.StatusOK {
+		t.Errorf("GET //acme.example.com/admin/: got %d, want 200", w.Code)
+	}
+}
@@ -1,6 +1,40 @@
 package gon
 
-import "path"
+import (
+	"net/http"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// H 是 map[string]any 的简写
+type H map[string]any
+
+// WrapF 用于将 http.HandlerFunc 包装为 HandlerFunc
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		f(c.Writer, c.Request)
+	}
+}
+
+// WrapH 用于将 http.Handler 包装为 HandlerFunc
+func WrapH(h http.Handler) HandlerFunc {
+	return func(c *Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// filterFlags 返回 Content-Type 等请求头中第一个 ' ' 或 ';' 之前的部分，去除 charset 等参数
+func filterFlags(content string) string {
+	for i, char := range content {
+		if char == ' ' || char == ';' {
+			return content[:i]
+		}
+	}
+	return content
+}
 
 // assert1 用来实现错误断言功能，不满足条件触发 panic
 func assert1(guard bool, text string) {
@@ -17,7 +51,6 @@ func lastChar(str string) uint8 {
 	return str[len(str)-1]
 }
 
-
 // joinPaths 用于将绝对路径和相对路径合并成一个完整的路径
 // absolutePath: 是前缀绝对路径
 // relativePath: 是需要进行拼接的路径
@@ -31,4 +64,174 @@ func joinPaths(absolutePath, relativePath string) string {
 		return finalPath + "/"
 	}
 	return finalPath
-}
\ No newline at end of file
+}
+
+// expandOptionalPath 将包含可选参数的路径展开为多个路径，没有可选参数时返回只包含原路径的切片
+// 以 '?' 结尾的参数段为可选参数段，例如 "/reports/:year?/:month?" 会被展开为
+// "/reports"、"/reports/:year" 和 "/reports/:year/:month"
+// 可选参数段只能出现在路径的末尾，即可选参数段之后的所有段也必须是可选参数段
+func expandOptionalPath(path string) []string {
+	segments := strings.Split(path, "/")
+	first := -1
+	for i, seg := range segments {
+		if len(seg) > 2 && seg[0] == ':' && seg[len(seg)-1] == '?' {
+			if first < 0 {
+				first = i
+			}
+			segments[i] = seg[:len(seg)-1]
+		} else if first >= 0 {
+			panic("optional parameters are only allowed at the end of the path in path '" + path + "'")
+		}
+	}
+	if first < 0 {
+		return []string{path}
+	}
+
+	paths := make([]string, 0, len(segments)-first+1)
+	for i := first; i <= len(segments); i++ {
+		p := strings.Join(segments[:i], "/")
+		if p == "" {
+			p = "/"
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// nameOfFunction 返回函数的完整名称，包括包路径
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// funcIdentity 返回处理函数值的唯一标识
+// 函数值本质上是一个指向闭包对象的指针，同一个函数创建的不同闭包会得到不同的标识，而同一个函数值的拷贝标识相同
+func funcIdentity(f HandlerFunc) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&f))
+}
+
+// cleanPath 是 path.Clean 的 URL 版本，返回 p 的规范路径，会消除路径中的 "." 和 ".." 元素
+// 会按照以下规则迭代处理，直到无法继续处理为止：
+//  1. 将多个连续的 "/" 替换为一个 "/"
+//  2. 消除每个 "." 路径元素（当前目录）
+//  3. 消除每个 ".." 路径元素（父目录）以及它前面的非 ".." 元素
+//  4. 消除根路径开头的 ".." 元素，即将路径开头的 "/.." 替换为 "/"
+//
+// 如果处理结果是空字符串，则返回 "/"，原路径末尾的 "/" 会被保留
+func cleanPath(p string) string {
+	const stackBufSize = 128
+	// 空字符串转换为 "/"
+	if p == "" {
+		return "/"
+	}
+
+	// 在栈上分配一个合适大小的缓冲区，避免常见情况下的内存分配，需要更大的缓冲区时再动态分配
+	buf := make([]byte, 0, stackBufSize)
+
+	n := len(p)
+
+	// 循环不变量：
+	//      r 是 p 中下一个需要处理的字节的位置
+	//      w 是 buf 中下一个需要写入的字节的位置
+
+	// 路径必须以 "/" 开头
+	r := 1
+	w := 1
+
+	if p[0] != '/' {
+		r = 0
+
+		if n+1 > stackBufSize {
+			buf = make([]byte, n+1)
+		} else {
+			buf = buf[:n+1]
+		}
+		buf[0] = '/'
+	}
+
+	trailing := n > 1 && p[n-1] == '/'
+
+	// 和 path 包不同，这个循环中没有开销较大的函数调用（除了需要时的 make），bufApp 的调用会被内联
+	for r < n {
+		switch {
+		case p[r] == '/':
+			// 空路径元素，末尾的 "/" 会在循环结束后补上
+			r++
+
+		case p[r] == '.' && r+1 == n:
+			trailing = true
+			r++
+
+		case p[r] == '.' && p[r+1] == '/':
+			// "." 元素
+			r += 2
+
+		case p[r] == '.' && p[r+1] == '.' && (r+2 == n || p[r+2] == '/'):
+			// ".." 元素，回退到上一个 "/"
+			r += 3
+
+			if w > 1 {
+				// 可以回退
+				w--
+
+				if len(buf) == 0 {
+					for w > 1 && p[w] != '/' {
+						w--
+					}
+				} else {
+					for w > 1 && buf[w] != '/' {
+						w--
+					}
+				}
+			}
+
+		default:
+			// 真正的路径元素，需要时添加 "/"
+			if w > 1 {
+				bufApp(&buf, p, w, '/')
+				w++
+			}
+
+			// 拷贝路径元素
+			for r < n && p[r] != '/' {
+				bufApp(&buf, p, w, p[r])
+				w++
+				r++
+			}
+		}
+	}
+
+	// 重新添加末尾的 "/"
+	if trailing && w > 1 {
+		bufApp(&buf, p, w, '/')
+		w++
+	}
+
+	// 如果原字符串没有被修改（或只是末尾被截断），直接返回原字符串的子串，否则使用缓冲区创建新的字符串
+	if len(buf) == 0 {
+		return p[:w]
+	}
+	return string(buf[:w])
+}
+
+// bufApp 是 cleanPath 的内部辅助函数，只在需要时才创建缓冲区
+func bufApp(buf *[]byte, s string, w int, c byte) {
+	b := *buf
+	if len(b) == 0 {
+		// 目前还没有修改原字符串，如果下一个字符和原字符串相同，则无需创建缓冲区
+		if s[w] == c {
+			return
+		}
+
+		// 否则使用栈上的缓冲区（如果足够大），或者在堆上分配新的缓冲区，然后拷贝之前所有的字符
+		length := len(s)
+		if length > cap(b) {
+			*buf = make([]byte, length)
+		} else {
+			*buf = (*buf)[:length]
+		}
+		b = *buf
+
+		copy(b, s[:w])
+	}
+	b[w] = c
+}
@@ -0,0 +1,91 @@
+package gon
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	router := New()
+	router.GET("/f/:id", WrapF(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("f"))
+	}))
+	router.GET("/h", WrapH(http.NotFoundHandler()))
+	router.GET("/next", WrapF(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Wrapped", "1")
+	}), func(c *Context) {
+		c.Status(http.StatusAccepted)
+	})
+
+	for _, tt := range []struct {
+		path, header, body string
+		code               int
+	}{
+		{"/f/1", "/f/1", "f", http.StatusCreated},
+		{"/h", "", "404 page not found\n", http.StatusNotFound},
+		{"/next", "", "", http.StatusAccepted}, // 包装后的处理函数之后的处理函数会继续执行
+	} {
+		w := performRequest(router, http.MethodGet, tt.path)
+		if w.Code != tt.code || w.Header().Get("X-Path") != tt.header || w.Body.String() != tt.body {
+			t.Errorf("GET %s: got %d %q %q", tt.path, w.Code, w.Header().Get("X-Path"), w.Body.String())
+		}
+	}
+}
+
+func TestExpandOptionalPath(t *testing.T) {
+	for _, tt := range []struct {
+		path  string
+		paths []string
+	}{
+		{"/users", []string{"/users"}},
+		{"/users/:id", []string{"/users/:id"}},
+		{"/users/:id?", []string{"/users", "/users/:id"}},
+		{"/:lang?", []string{"/", "/:lang"}},
+		{"/archive/:year<int>?/:month?", []string{"/archive", "/archive/:year<int>", "/archive/:year<int>/:month"}},
+		{"/files/:?", []string{"/files/:?"}}, // 没有参数名的 "?" 不是可选参数
+	} {
+		if got := expandOptionalPath(tt.path); !slices.Equal(got, tt.paths) {
+			t.Errorf("expandOptionalPath(%q) = %q, want %q", tt.path, got, tt.paths)
+		}
+	}
+
+	for _, path := range []string{"/users/:id?/posts", "/:a?/:b"} {
+		if err := catchPanic(func() { expandOptionalPath(path) }); err == nil {
+			t.Errorf("expandOptionalPath(%q) did not panic", path)
+		}
+	}
+}
+
+func TestOptionalParamRoutes(t *testing.T) {
+	router := New()
+	var id string
+	router.GET("/users/:id?", func(c *Context) { id = c.Param("id") })
+
+	// Routes 返回每个展开后的路由
+	var paths []string
+	for _, route := range router.Routes() {
+		paths = append(paths, route.Path)
+	}
+	slices.Sort(paths)
+	if want := []string{"/users", "/users/:id"}; !slices.Equal(paths, want) {
+		t.Errorf("Routes paths = %q, want %q", paths, want)
+	}
+
+	for _, tt := range []struct {
+		path string
+		code int
+		id   string
+	}{
+		{"/users", http.StatusOK, ""},
+		{"/users/42", http.StatusOK, "42"},
+		{"/users/42/posts", http.StatusNotFound, ""},
+	} {
+		id = ""
+		if w := performRequest(router, http.MethodGet, tt.path); w.Code != tt.code || id != tt.id {
+			t.Errorf("GET %s: got %d %q, want %d %q", tt.path, w.Code, id, tt.code, tt.id)
+		}
+	}
+}